@@ -102,7 +102,7 @@ func run(limit int, bits string) uint32 {
 	if err != nil {
 		panic(err)
 	}
-	r, err := solrHttpRetrier.Select(replicas, Query("*:*"), FilterQuery("last_name:"+uuid), Rows(uint32(limit)))
+	r, err := solrHttpRetrier.Select(replicas, Query(MatchAll().String()), FilterQuery(Term("last_name", uuid).String()), Rows(uint32(limit)))
 	if err != nil {
 		panic(fmt.Sprintf("error %v getting count on %v", err, replicas))
 	}
@@ -115,7 +115,7 @@ func run(limit int, bits string) uint32 {
 		if err != nil {
 			panic(err)
 		}
-		check, err := solrHttpRetrier.Select(replicas, Query("id:"+shardKey+"!rando*"), FilterQuery("last_name:"+uuid), Rows(uint32(0)))
+		check, err := solrHttpRetrier.Select(replicas, Query(Prefix("id", shardKey+"!rando").String()), FilterQuery(Term("last_name", uuid).String()), Rows(uint32(0)))
 		if err != nil {
 			panic(err)
 		}
@@ -1,38 +1,142 @@
 package solr
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 var ErrNotFound = NewNotFoundError("Not found")
 
+// Error categories, every SolrError matches the category of its status with errors.Is
+var (
+	ErrBadRequest      = errors.New("solr: bad request")
+	ErrConflict        = errors.New("solr: conflict")
+	ErrUnavailable     = errors.New("solr: unavailable")
+	ErrAuth            = errors.New("solr: authentication failed")
+	ErrMinRF           = errors.New("solr: achieved rf is below min_rf")
+	ErrPayloadTooLarge = errors.New("solr: payload too large")
+)
+
+// SolrError is an error response from solr. When solr answers with its json error body
+// Code, Msg, Metadata and Trace are filled from it, otherwise Msg holds the raw body
 type SolrError struct {
 	errorMessage string
+	// Status is the http status, or the responseHeader status for errors reported in a 200
+	Status int
+	// Code, Msg and Trace are error.code, error.msg and error.trace of the solr error body
+	Code  int
+	Msg   string
+	Trace string
+	// Metadata is error.metadata, it holds error-class and root-error-class
+	Metadata map[string]string
+	// URL is the url of the request and Node the node it was sent to
+	URL  string
+	Node string
 }
 
 func (err SolrError) Error() string {
 	return err.errorMessage
 }
 
+// Is matches the error category of the status
+func (err SolrError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return err.Status == http.StatusNotFound
+	case ErrBadRequest:
+		return err.Status == http.StatusBadRequest
+	case ErrConflict:
+		return err.Status == http.StatusConflict
+	case ErrUnavailable:
+		return err.Status == http.StatusServiceUnavailable || err.Status == http.StatusBadGateway || err.Status == http.StatusGatewayTimeout
+	case ErrAuth:
+		return err.Status == http.StatusUnauthorized || err.Status == http.StatusForbidden
+	case ErrPayloadTooLarge:
+		return err.Status == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// RootErrorClass returns the java class of the root exception behind the error, if solr reported it
+func (err SolrError) RootErrorClass() string {
+	if class, ok := err.Metadata["root-error-class"]; ok {
+		return class
+	}
+	return err.Metadata["error-class"]
+}
+
 func NewSolrError(status int, message string) error {
-	return SolrError{errorMessage: fmt.Sprintf("received error response from solr status: %d message: %s", status, message)}
+	return SolrError{errorMessage: fmt.Sprintf("received error response from solr status: %d message: %s", status, message), Status: status, Msg: message}
+}
+
+type solrErrorBody struct {
+	Error struct {
+		Metadata []string `json:"metadata"`
+		Msg      string   `json:"msg"`
+		Code     int      `json:"code"`
+		Trace    string   `json:"trace"`
+	} `json:"error"`
+}
+
+// ParseSolrError builds a SolrError from an error response body, json error bodies are parsed
+// into their fields and anything else, like the html pages jetty serves, is kept as the message
+func ParseSolrError(status int, body []byte) SolrError {
+	var e solrErrorBody
+	if err := json.Unmarshal(body, &e); err != nil || (e.Error.Msg == "" && e.Error.Code == 0) {
+		return NewSolrError(status, strings.TrimSpace(string(body))).(SolrError)
+	}
+	solrErr := NewSolrError(status, e.Error.Msg).(SolrError)
+	solrErr.Code = e.Error.Code
+	solrErr.Trace = e.Error.Trace
+	solrErr.Metadata = metadataMap(e.Error.Metadata)
+	return solrErr
+}
+
+// metadataMap turns solr's flat [key, value, key, value] metadata into a map
+func metadataMap(metadata []string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(metadata)/2)
+	for i := 0; i+1 < len(metadata); i += 2 {
+		m[metadata[i]] = metadata[i+1]
+	}
+	return m
 }
 
 func NewSolrRFError(rf, minRF int) error {
-	return SolrMinRFError{SolrError{errorMessage: fmt.Sprintf("received error response from solr: rf (%d) is < min_rf (%d)", rf, minRF)}, rf}
+	return SolrMinRFError{SolrError{errorMessage: fmt.Sprintf("received error response from solr: rf (%d) is < min_rf (%d)", rf, minRF)}, rf, minRF}
 }
 
 type SolrMinRFError struct {
 	SolrError
+	RF    int
 	MinRF int
 }
 
+func (err SolrMinRFError) Is(target error) bool {
+	return target == ErrMinRF || err.SolrError.Is(target)
+}
+
+func (err SolrMinRFError) Unwrap() error {
+	return err.SolrError
+}
+
 type SolrInternalError struct {
 	SolrError
 }
 
 func NewSolrInternalError(status int, message string) error {
-	return SolrInternalError{SolrError{errorMessage: fmt.Sprintf("received error response from solr status: %d message: %s", status, message)}}
+	return SolrInternalError{NewSolrError(status, message).(SolrError)}
+}
+
+func (err SolrInternalError) Unwrap() error {
+	return err.SolrError
 }
 
 type SolrLeaderError struct {
@@ -43,6 +147,10 @@ func NewSolrLeaderError(docID string) error {
 	return SolrLeaderError{SolrError{errorMessage: fmt.Sprintf("Cannot find leader for doc %s", docID)}}
 }
 
+func (err SolrLeaderError) Unwrap() error {
+	return err.SolrError
+}
+
 type SolrBatchError struct {
 	error
 }
@@ -51,12 +159,60 @@ func NewSolrBatchError(err error) error {
 	return SolrBatchError{error: err}
 }
 
+func (err SolrBatchError) Unwrap() error {
+	return err.error
+}
+
+// PayloadTooLargeError reports the docs of a batch that were still too large once sent on their own,
+// the rest of the batch was indexed. It matches ErrPayloadTooLarge with errors.Is, and ErrMinRF too
+// when the docs that were indexed missed min_rf
+type PayloadTooLargeError struct {
+	SolrError
+	// Indexes are the positions of the rejected docs in the batch and Docs the docs themselves
+	Indexes []int
+	Docs    []interface{}
+	// MinRFError is set when the rest of the batch was indexed below min_rf
+	MinRFError *SolrMinRFError
+}
+
+func (err PayloadTooLargeError) Error() string {
+	msg := fmt.Sprintf("%d docs of the batch are too large to index at positions %v: %s", len(err.Indexes), err.Indexes, err.SolrError.Error())
+	if err.MinRFError != nil {
+		msg += "; " + err.MinRFError.Error()
+	}
+	return msg
+}
+
+func (err PayloadTooLargeError) Is(target error) bool {
+	return target == ErrMinRF && err.MinRFError != nil
+}
+
+func (err PayloadTooLargeError) Unwrap() error {
+	return err.SolrError
+}
+
 type SolrParseError struct {
 	SolrError
 }
 
 func NewSolrParseError(status int, message string) error {
-	return SolrInternalError{SolrError{errorMessage: fmt.Sprintf("received error response from solr status: %d message: %s", status, message)}}
+	return SolrParseError{NewSolrError(status, message).(SolrError)}
+}
+
+func (err SolrParseError) Unwrap() error {
+	return err.SolrError
+}
+
+type SolrExportError struct {
+	SolrError
+}
+
+func NewSolrExportError(message string) error {
+	return SolrExportError{SolrError{errorMessage: fmt.Sprintf("received exception in solr export stream: %s", message), Msg: message}}
+}
+
+func (err SolrExportError) Unwrap() error {
+	return err.SolrError
 }
 
 type SolrMapParseError struct {
@@ -73,6 +229,51 @@ func NewSolrMapParseError(bucket string, userId int, m map[string]interface{}) e
 	return SolrMapParseError{bucket, m, userId}
 }
 
+var (
+	versionConflictPattern = regexp.MustCompile(`version conflict for (\S+) expected=(-?\d+) actual=(-?\d+)`)
+	docNotFoundPattern     = regexp.MustCompile(`Document not found for update\.\s+id=(\S+)`)
+)
+
+// VersionConflictError is the 409 solr answers when the _version_ asserted by an update does not match
+// the doc, CurrentVersion is 0 when the doc does not exist. It matches ErrConflict with errors.Is
+type VersionConflictError struct {
+	SolrError
+	ID              string
+	ExpectedVersion int64
+	CurrentVersion  int64
+}
+
+// NewVersionConflictError parses the doc id and versions out of the message of a 409
+func NewVersionConflictError(solrErr SolrError) error {
+	conflict := VersionConflictError{SolrError: solrErr}
+	if m := versionConflictPattern.FindStringSubmatch(solrErr.Msg); m != nil {
+		conflict.ID = m[1]
+		conflict.ExpectedVersion, _ = strconv.ParseInt(m[2], 10, 64)
+		conflict.CurrentVersion, _ = strconv.ParseInt(m[3], 10, 64)
+	} else if m := docNotFoundPattern.FindStringSubmatch(solrErr.Msg); m != nil {
+		conflict.ID = m[1]
+		conflict.ExpectedVersion = VersionMustExist
+	}
+	return conflict
+}
+
+func (err VersionConflictError) Unwrap() error {
+	return err.SolrError
+}
+
+// DocNotFoundError reports a doc id that does not exist, it matches ErrNotFound with errors.Is
+type DocNotFoundError struct {
+	ID string
+}
+
+func (err DocNotFoundError) Error() string {
+	return fmt.Sprintf("doc %s not found", err.ID)
+}
+
+func (err DocNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 type NotFoundError struct {
 	errorMessage string
 }
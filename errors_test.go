@@ -0,0 +1,59 @@
+package solr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Solr Errors", func() {
+	It("parses the json error body", func() {
+		solrErr := solr.ParseSolrError(http.StatusBadRequest, []byte(`{"responseHeader":{"status":400,"QTime":1},"error":{"metadata":["error-class","org.apache.solr.common.SolrException","root-error-class","org.apache.solr.search.SyntaxError"],"msg":"undefined field foo","code":400}}`))
+		Expect(solrErr.Status).To(Equal(http.StatusBadRequest))
+		Expect(solrErr.Code).To(Equal(400))
+		Expect(solrErr.Msg).To(Equal("undefined field foo"))
+		Expect(solrErr.RootErrorClass()).To(Equal("org.apache.solr.search.SyntaxError"))
+		Expect(solrErr.Error()).To(ContainSubstring("400"))
+	})
+
+	It("keeps a non json body as the message", func() {
+		solrErr := solr.ParseSolrError(http.StatusUnauthorized, []byte("<html>401 Unauthorized</html>"))
+		Expect(solrErr.Msg).To(Equal("<html>401 Unauthorized</html>"))
+		Expect(errors.Is(solrErr, solr.ErrAuth)).To(BeTrue())
+	})
+
+	It("matches categories through wrapping error types", func() {
+		Expect(errors.Is(solr.NewSolrInternalError(http.StatusServiceUnavailable, "down"), solr.ErrUnavailable)).To(BeTrue())
+		Expect(errors.Is(solr.NewSolrError(http.StatusConflict, "version conflict"), solr.ErrConflict)).To(BeTrue())
+		Expect(errors.Is(solr.NewSolrError(http.StatusConflict, "version conflict"), solr.ErrBadRequest)).To(BeFalse())
+		Expect(errors.Is(solr.NewSolrRFError(1, 2), solr.ErrMinRF)).To(BeTrue())
+
+		var solrErr solr.SolrError
+		Expect(errors.As(solr.NewSolrInternalError(http.StatusInternalServerError, "boom"), &solrErr)).To(BeTrue())
+		Expect(solrErr.Status).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("reports the achieved and the required rf", func() {
+		rfErr := solr.NewSolrRFError(1, 2).(solr.SolrMinRFError)
+		Expect(rfErr.RF).To(Equal(1))
+		Expect(rfErr.MinRF).To(Equal(2))
+	})
+
+	It("returns structured errors from select", func() {
+		cli := &fakeHTTPer{status: http.StatusBadRequest, body: `{"error":{"msg":"undefined field foo","code":400}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.SelectContext(context.Background(), []string{"http://a.foo.bar"}, solr.Query("foo:bar"))
+		Expect(r.Status).To(Equal(http.StatusBadRequest))
+		var solrErr solr.SolrError
+		Expect(errors.As(err, &solrErr)).To(BeTrue())
+		Expect(solrErr.Msg).To(Equal("undefined field foo"))
+		Expect(solrErr.Node).To(Equal("http://a.foo.bar"))
+		Expect(solrErr.URL).To(Equal("http://a.foo.bar/solrtest/select"))
+		Expect(errors.Is(err, solr.ErrBadRequest)).To(BeTrue())
+	})
+})
@@ -1,6 +1,7 @@
 package solr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -28,12 +29,57 @@ type SolrLocator interface {
 	GetLeadersAndReplicas(docID string) ([]string, error)
 }
 
+// SolrShardLocator is a SolrLocator that also knows the shards of the collection, the locator
+// returned by SolrZK.GetSolrLocator implements it. The helpers that route per shard take a SolrLocator
+// and return an error when it does not implement SolrShardLocator
+type SolrShardLocator interface {
+	SolrLocator
+	GetShardCores() (map[string][]string, error)
+	GroupByShard(docIDs []string) (map[string][]string, error)
+}
+
+// groupByShard is GroupByShard on a locator that implements SolrShardLocator
+func groupByShard(locator SolrLocator, docIDs []string) (map[string][]string, error) {
+	shards, ok := locator.(SolrShardLocator)
+	if !ok {
+		return nil, fmt.Errorf("[go-solr] locator %T does not implement SolrShardLocator", locator)
+	}
+	return shards.GroupByShard(docIDs)
+}
+
+// getShardCores is GetShardCores on a locator that implements SolrShardLocator
+func getShardCores(locator SolrLocator) (map[string][]string, error) {
+	shards, ok := locator.(SolrShardLocator)
+	if !ok {
+		return nil, fmt.Errorf("[go-solr] locator %T does not implement SolrShardLocator", locator)
+	}
+	return shards.GetShardCores()
+}
+
 type SolrHTTP interface {
 	Select(nodeUris []string, opts ...func(url.Values)) (SolrResponse, error)
 	Update(nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) error
 	Logger() Logger
 }
 
+// SolrHTTPContext is a SolrHTTP with context aware selects and updates and the other solr handlers,
+// the clients returned by NewSolrHTTP and NewSolrHttpRetrier implement it
+type SolrHTTPContext interface {
+	SolrHTTP
+	SelectContext(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SolrResponse, error)
+	SelectInto(ctx context.Context, nodeUris []string, dst interface{}, opts ...func(url.Values)) (SolrResponse, error)
+	SelectStream(ctx context.Context, nodeUris []string, fn func(header StreamHeader, doc map[string]interface{}) error, opts ...func(url.Values)) (SolrResponse, error)
+	RealTimeGet(ctx context.Context, nodeUris []string, ids []string, opts ...func(url.Values)) (SolrResponse, error)
+	Export(ctx context.Context, coreUris []string, fn func(doc map[string]interface{}) error, opts ...func(url.Values)) (ExportResponse, error)
+	Suggest(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SuggestResponse, error)
+	MoreLikeThis(ctx context.Context, nodeUris []string, text string, opts ...func(url.Values)) (MoreLikeThisResponse, error)
+	MoreLikeThisCores(ctx context.Context, coreUris []string, opts ...func(url.Values)) (MoreLikeThisResponse, error)
+	Terms(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (TermsResponse, error)
+	UpdateContext(ctx context.Context, nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) (UpdateResult, error)
+	Commit(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error)
+	Optimize(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error)
+}
+
 type Logger interface {
 	Error(err error)
 	Info(v ...interface{})
@@ -0,0 +1,158 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// atomic update modifiers
+const (
+	atomicSet         = "set"
+	atomicAdd         = "add"
+	atomicAddDistinct = "add-distinct"
+	atomicRemove      = "remove"
+	atomicRemoveRegex = "removeregex"
+	atomicInc         = "inc"
+)
+
+// AtomicUpdate is a partial update of a single doc, only the modified fields are sent and solr
+// rebuilds the rest of the doc from its stored fields. It encodes to the json solr expects so it
+// can be passed to Update as is
+type AtomicUpdate struct {
+	id      string
+	version int64
+	fields  map[string]map[string]interface{}
+}
+
+// NewAtomicUpdate starts a partial update of the doc with the given id
+func NewAtomicUpdate(id string) *AtomicUpdate {
+	return &AtomicUpdate{id: id, fields: make(map[string]map[string]interface{})}
+}
+
+// Set replaces the value of field, a nil value removes the field
+func (u *AtomicUpdate) Set(field string, value interface{}) *AtomicUpdate {
+	return u.modify(field, atomicSet, value)
+}
+
+// Add appends values to a multi-valued field
+func (u *AtomicUpdate) Add(field string, values ...interface{}) *AtomicUpdate {
+	return u.modify(field, atomicAdd, values)
+}
+
+// AddDistinct appends the values that are not already present to a multi-valued field
+func (u *AtomicUpdate) AddDistinct(field string, values ...interface{}) *AtomicUpdate {
+	return u.modify(field, atomicAddDistinct, values)
+}
+
+// Remove removes every occurrence of values from a multi-valued field
+func (u *AtomicUpdate) Remove(field string, values ...interface{}) *AtomicUpdate {
+	return u.modify(field, atomicRemove, values)
+}
+
+// RemoveRegex removes the values matching any of the java regular expressions from a multi-valued field
+func (u *AtomicUpdate) RemoveRegex(field string, patterns ...string) *AtomicUpdate {
+	return u.modify(field, atomicRemoveRegex, patterns)
+}
+
+// Inc increments a numeric field by delta
+func (u *AtomicUpdate) Inc(field string, delta interface{}) *AtomicUpdate {
+	return u.modify(field, atomicInc, delta)
+}
+
+func (u *AtomicUpdate) modify(field string, modifier string, value interface{}) *AtomicUpdate {
+	if _, ok := u.fields[field]; !ok {
+		u.fields[field] = make(map[string]interface{})
+	}
+	u.fields[field][modifier] = value
+	return u
+}
+
+// ID returns the id of the doc being updated
+func (u *AtomicUpdate) ID() string {
+	return u.id
+}
+
+// Doc returns the update as the doc sent to solr
+func (u *AtomicUpdate) Doc() map[string]interface{} {
+	doc := make(map[string]interface{}, len(u.fields)+1)
+	for field, modifiers := range u.fields {
+		doc[field] = modifiers
+	}
+	doc[uniqueKey] = u.id
+	if u.version != 0 {
+		doc[versionField] = u.version
+	}
+	return doc
+}
+
+func (u *AtomicUpdate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Doc())
+}
+
+// Validate checks the update carries an id to route on and modifies at least one field
+func (u *AtomicUpdate) Validate() error {
+	if u.id == "" {
+		return fmt.Errorf("[go-solr] atomic update: id is required to route the update")
+	}
+	if len(u.fields) == 0 {
+		return fmt.Errorf("[go-solr] atomic update: doc %s has no modified fields", u.id)
+	}
+	if _, ok := u.fields[uniqueKey]; ok {
+		return fmt.Errorf("[go-solr] atomic update: doc %s cannot modify its id", u.id)
+	}
+	if _, ok := u.fields[versionField]; ok {
+		return fmt.Errorf("[go-solr] atomic update: doc %s cannot modify its version, use Version", u.id)
+	}
+	return nil
+}
+
+// UpdateAtomic validates the partial updates, groups them by shard and sends each group to the
+// leader and replicas found with GetLeadersAndReplicas through cli.UpdateContext, so a SolrHttpRetrier
+// retries them like any other update. The results of the groups are merged, with the version of every
+// updated doc in Adds when Versions is set. It stops at the first group that fails
+func UpdateAtomic(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, updates []*AtomicUpdate, opts ...func(url.Values)) (UpdateResult, error) {
+	var result UpdateResult
+	byID := make(map[string]*AtomicUpdate, len(updates))
+	ids := make([]string, 0, len(updates))
+	for _, update := range updates {
+		if err := update.Validate(); err != nil {
+			return result, err
+		}
+		if _, ok := byID[update.id]; ok {
+			return result, fmt.Errorf("[go-solr] atomic update: doc %s is updated more than once", update.id)
+		}
+		byID[update.id] = update
+		ids = append(ids, update.id)
+	}
+
+	groups, err := groupByShard(locator, ids)
+	if err != nil {
+		return result, err
+	}
+	first := true
+	for _, group := range groups {
+		nodeUris, err := locator.GetLeadersAndReplicas(group[0])
+		if err != nil {
+			return result, err
+		}
+		if len(nodeUris) == 0 {
+			return result, NewSolrLeaderError(group[0])
+		}
+		docs := make([]*AtomicUpdate, len(group))
+		for i, id := range group {
+			docs[i] = byID[id]
+		}
+		r, err := cli.UpdateContext(ctx, nodeUris, false, docs, opts...)
+		if first {
+			result, first = r, false
+		} else {
+			result = mergeUpdateResults(result, r)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
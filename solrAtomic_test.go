@@ -0,0 +1,59 @@
+package solr_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Atomic Update", func() {
+	It("encodes every modifier", func() {
+		update := solr.NewAtomicUpdate("shard1!a").
+			Set("first_name", "shawn").
+			Add("tags", "a", "b").
+			AddDistinct("lists", 1).
+			Remove("tags", "c").
+			RemoveRegex("emails", ".*@old.com").
+			Inc("opens", 2)
+		b, err := json.Marshal(update)
+		Expect(err).To(BeNil())
+		Expect(string(b)).To(MatchJSON(`{
+			"id": "shard1!a",
+			"first_name": {"set": "shawn"},
+			"tags": {"add": ["a", "b"], "remove": ["c"]},
+			"lists": {"add-distinct": [1]},
+			"emails": {"removeregex": [".*@old.com"]},
+			"opens": {"inc": 2}
+		}`))
+	})
+
+	It("validates the update", func() {
+		Expect(solr.NewAtomicUpdate("").Set("a", 1).Validate()).To(Not(BeNil()))
+		Expect(solr.NewAtomicUpdate("shard1!a").Validate()).To(Not(BeNil()))
+		Expect(solr.NewAtomicUpdate("shard1!a").Set("id", "b").Validate()).To(Not(BeNil()))
+		Expect(solr.NewAtomicUpdate("shard1!a").Set("a", 1).Validate()).To(BeNil())
+	})
+
+	It("sends updates to the leader of their shard", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0,"rf":1,"min_rf":1},"adds":["shard1!a",11,"shard1!b",12]}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		locator := &fakeLocator{shardNodes: map[string][]string{"shard1": {"http://leader1.foo.bar"}}}
+		r, err := solr.UpdateAtomic(context.Background(), solrHttp, locator, []*solr.AtomicUpdate{
+			solr.NewAtomicUpdate("shard1!a").Inc("opens", 1),
+			solr.NewAtomicUpdate("shard1!b").Inc("opens", 1),
+		}, solr.Versions(true))
+		Expect(err).To(BeNil())
+		Expect(r.Adds).To(Equal(solr.DocVersions{"shard1!a": 11, "shard1!b": 12}))
+		Expect(cli.requests).To(HaveLen(1))
+		Expect(cli.requests[0].URL.Path).To(Equal("/solrtest/update"))
+		Expect(cli.requests[0].URL.Query().Get("versions")).To(Equal("true"))
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		Expect(string(body)).To(MatchJSON(`[{"id":"shard1!a","opens":{"inc":1}},{"id":"shard1!b","opens":{"inc":1}}]`))
+	})
+})
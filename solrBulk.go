@@ -0,0 +1,355 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrBulkIndexerClosed is returned when docs are added to a BulkIndexer after Close
+var ErrBulkIndexerClosed = errors.New("[go-solr] bulk indexer: closed")
+
+// BulkIndexerStats counts the docs that went through a BulkIndexer
+type BulkIndexerStats struct {
+	Added   int
+	Indexed int
+	Failed  int
+	Batches int
+}
+
+// BulkIndexer groups docs by shard, batches them and sends each batch to the leader and replicas
+// of its shard from a bounded pool of workers. A batch is sent once it reaches the batch size or byte
+// limit, or when the flush interval ticks. Add blocks while every worker is busy and the queue is full
+type BulkIndexer struct {
+	ctx           context.Context
+	cli           SolrHTTPContext
+	locator       SolrLocator
+	batchSize     int
+	batchBytes    int
+	flushInterval time.Duration
+	workers       int
+	onSuccess     func(doc map[string]interface{})
+	onFailure     func(doc map[string]interface{}, err error)
+	updateOpts    []func(url.Values)
+
+	lock     sync.Mutex
+	pending  map[string]*bulkBatch
+	closed   bool
+	stats    BulkIndexerStats
+	batches  chan *bulkBatch
+	inflight sync.WaitGroup
+	running  sync.WaitGroup
+	stop     chan struct{}
+	// callbackLock serializes the callbacks
+	callbackLock sync.Mutex
+}
+
+type bulkBatch struct {
+	shard string
+	ids   []string
+	docs  []map[string]interface{}
+	bytes int
+}
+
+// NewBulkIndexer starts the workers of a BulkIndexer, ctx bounds every request they send.
+// Close must be called to send the last batches and stop the workers
+func NewBulkIndexer(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, options ...func(*BulkIndexer)) *BulkIndexer {
+	bi := &BulkIndexer{
+		ctx:           ctx,
+		cli:           cli,
+		locator:       locator,
+		batchSize:     500,
+		batchBytes:    5 << 20,
+		flushInterval: time.Second,
+		workers:       4,
+		pending:       make(map[string]*bulkBatch),
+		stop:          make(chan struct{}),
+	}
+	for _, opt := range options {
+		opt(bi)
+	}
+	if bi.workers < 1 {
+		bi.workers = 1
+	}
+	bi.batches = make(chan *bulkBatch, bi.workers)
+	for i := 0; i < bi.workers; i++ {
+		bi.running.Add(1)
+		go bi.work()
+	}
+	if bi.flushInterval > 0 {
+		go bi.tick()
+	}
+	return bi
+}
+
+// Add queues doc for indexing, the doc must carry a string id to be routed to its shard.
+// It blocks until the batch it completes is handed to a worker or ctx is done
+func (bi *BulkIndexer) Add(ctx context.Context, doc map[string]interface{}) error {
+	id := GetDocIdFromDoc(doc)
+	if id == "" {
+		return fmt.Errorf("[go-solr] bulk indexer: doc has no string id")
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	groups, err := groupByShard(bi.locator, []string{id})
+	if err != nil {
+		return err
+	}
+	var shard string
+	for s := range groups {
+		shard = s
+	}
+
+	bi.lock.Lock()
+	if bi.closed {
+		bi.lock.Unlock()
+		return ErrBulkIndexerClosed
+	}
+	batch, ok := bi.pending[shard]
+	if !ok {
+		batch = &bulkBatch{shard: shard}
+		bi.pending[shard] = batch
+	}
+	batch.ids = append(batch.ids, id)
+	batch.docs = append(batch.docs, doc)
+	batch.bytes += len(b)
+	bi.stats.Added++
+	var full *bulkBatch
+	if len(batch.docs) >= bi.batchSize || batch.bytes >= bi.batchBytes {
+		full = batch
+		delete(bi.pending, shard)
+		bi.inflight.Add(1)
+	}
+	bi.lock.Unlock()
+
+	if full == nil {
+		return nil
+	}
+	return bi.send(ctx, full)
+}
+
+// Consume adds the docs read from docs until it is closed, it stops at the first doc Add rejects
+func (bi *BulkIndexer) Consume(ctx context.Context, docs <-chan map[string]interface{}) error {
+	for {
+		select {
+		case doc, ok := <-docs:
+			if !ok {
+				return nil
+			}
+			if err := bi.Add(ctx, doc); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Flush hands the pending batches to the workers without waiting for them to be indexed
+func (bi *BulkIndexer) Flush(ctx context.Context) error {
+	bi.lock.Lock()
+	if bi.closed {
+		bi.lock.Unlock()
+		return ErrBulkIndexerClosed
+	}
+	batches := bi.takePending()
+	bi.lock.Unlock()
+	return bi.sendAll(ctx, batches)
+}
+
+// Close sends the pending batches, waits for every batch to be indexed and stops the workers.
+// When ctx is done first the remaining batches are left to the workers and the context error is returned
+func (bi *BulkIndexer) Close(ctx context.Context) (BulkIndexerStats, error) {
+	bi.lock.Lock()
+	if bi.closed {
+		bi.lock.Unlock()
+		return bi.Stats(), ErrBulkIndexerClosed
+	}
+	bi.closed = true
+	batches := bi.takePending()
+	bi.lock.Unlock()
+	close(bi.stop)
+
+	err := bi.sendAll(ctx, batches)
+	done := make(chan struct{})
+	go func() {
+		bi.inflight.Wait()
+		close(bi.batches)
+		bi.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return bi.Stats(), err
+}
+
+// Stats returns the counts so far
+func (bi *BulkIndexer) Stats() BulkIndexerStats {
+	bi.lock.Lock()
+	defer bi.lock.Unlock()
+	return bi.stats
+}
+
+// takePending must be called with the lock held
+func (bi *BulkIndexer) takePending() []*bulkBatch {
+	batches := make([]*bulkBatch, 0, len(bi.pending))
+	for shard, batch := range bi.pending {
+		batches = append(batches, batch)
+		delete(bi.pending, shard)
+		bi.inflight.Add(1)
+	}
+	return batches
+}
+
+func (bi *BulkIndexer) sendAll(ctx context.Context, batches []*bulkBatch) error {
+	var err error
+	for _, batch := range batches {
+		if sendErr := bi.send(ctx, batch); sendErr != nil && err == nil {
+			err = sendErr
+		}
+	}
+	return err
+}
+
+// send hands the batch to a worker, a batch that cannot be handed over is reported as failed
+func (bi *BulkIndexer) send(ctx context.Context, batch *bulkBatch) error {
+	select {
+	case bi.batches <- batch:
+		return nil
+	case <-ctx.Done():
+		bi.report(batch, ctx.Err())
+		bi.inflight.Done()
+		return ctx.Err()
+	case <-bi.ctx.Done():
+		bi.report(batch, bi.ctx.Err())
+		bi.inflight.Done()
+		return bi.ctx.Err()
+	}
+}
+
+func (bi *BulkIndexer) work() {
+	defer bi.running.Done()
+	for batch := range bi.batches {
+		bi.report(batch, bi.index(batch))
+		bi.inflight.Done()
+	}
+}
+
+func (bi *BulkIndexer) index(batch *bulkBatch) error {
+	nodeUris, err := bi.locator.GetLeadersAndReplicas(batch.ids[0])
+	if err != nil {
+		return err
+	}
+	if len(nodeUris) == 0 {
+		return NewSolrLeaderError(batch.ids[0])
+	}
+	_, err = bi.cli.UpdateContext(bi.ctx, nodeUris, false, batch.docs, bi.updateOpts...)
+	return err
+}
+
+func (bi *BulkIndexer) report(batch *bulkBatch, err error) {
+	// only the docs that were too large on their own failed when solr split the batch
+	failed := make(map[int]bool)
+	if tooLarge, ok := err.(PayloadTooLargeError); ok {
+		for _, i := range tooLarge.Indexes {
+			failed[i] = true
+		}
+	} else if err != nil {
+		for i := range batch.docs {
+			failed[i] = true
+		}
+	}
+
+	bi.lock.Lock()
+	bi.stats.Batches++
+	bi.stats.Failed += len(failed)
+	bi.stats.Indexed += len(batch.docs) - len(failed)
+	bi.lock.Unlock()
+
+	bi.callbackLock.Lock()
+	defer bi.callbackLock.Unlock()
+	for i, doc := range batch.docs {
+		if failed[i] && bi.onFailure != nil {
+			bi.onFailure(doc, err)
+		} else if !failed[i] && bi.onSuccess != nil {
+			bi.onSuccess(doc)
+		}
+	}
+}
+
+func (bi *BulkIndexer) tick() {
+	ticker := time.NewTicker(bi.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			bi.Flush(bi.ctx)
+		case <-bi.stop:
+			return
+		case <-bi.ctx.Done():
+			return
+		}
+	}
+}
+
+// BulkBatchSize sets the number of docs that fills a batch, 500 by default
+func BulkBatchSize(docs int) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.batchSize = docs
+	}
+}
+
+// BulkBatchBytes sets the json encoded size that fills a batch, 5MB by default
+func BulkBatchBytes(bytes int) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.batchBytes = bytes
+	}
+}
+
+// BulkFlushInterval sets how often the batches are sent whatever their size, one second by default.
+// Zero disables the periodic flush
+func BulkFlushInterval(d time.Duration) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.flushInterval = d
+	}
+}
+
+// BulkWorkers sets the number of batches sent concurrently, 4 by default
+func BulkWorkers(workers int) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.workers = workers
+	}
+}
+
+// BulkOnSuccess is called for every indexed doc. The callbacks are never called concurrently
+// and must not call Add
+func BulkOnSuccess(fn func(doc map[string]interface{})) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.onSuccess = fn
+	}
+}
+
+// BulkOnFailure is called for every doc of a failed batch with the error of the batch
+func BulkOnFailure(fn func(doc map[string]interface{}, err error)) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.onFailure = fn
+	}
+}
+
+// BulkUpdateOptions sets the params sent with every batch, like Commit or CommitWithin
+func BulkUpdateOptions(opts ...func(url.Values)) func(*BulkIndexer) {
+	return func(bi *BulkIndexer) {
+		bi.updateOpts = opts
+	}
+}
@@ -0,0 +1,80 @@
+package solr_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Bulk Indexer", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	var locator *fakeLocator
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0,"rf":1,"min_rf":1}}`}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		locator = &fakeLocator{shardNodes: map[string][]string{
+			"shard1": {"http://leader1.foo.bar"},
+			"shard2": {"http://leader2.foo.bar"},
+		}}
+	})
+
+	It("batches docs per shard leader", func() {
+		var indexed []string
+		bi := solr.NewBulkIndexer(context.Background(), solrHttp, locator, solr.BulkBatchSize(2), solr.BulkFlushInterval(0),
+			solr.BulkOnSuccess(func(doc map[string]interface{}) {
+				indexed = append(indexed, solr.GetDocIdFromDoc(doc))
+			}))
+		for _, id := range []string{"shard1!a", "shard2!b", "shard1!c", "shard1!d"} {
+			Expect(bi.Add(context.Background(), map[string]interface{}{"id": id})).To(BeNil())
+		}
+		stats, err := bi.Close(context.Background())
+		Expect(err).To(BeNil())
+		Expect(stats).To(Equal(solr.BulkIndexerStats{Added: 4, Indexed: 4, Batches: 3}))
+		Expect(indexed).To(ConsistOf("shard1!a", "shard2!b", "shard1!c", "shard1!d"))
+
+		sizes := map[string][]int{}
+		for _, req := range cli.requests {
+			var docs []map[string]interface{}
+			body, _ := ioutil.ReadAll(req.Body)
+			Expect(json.Unmarshal(body, &docs)).To(BeNil())
+			sizes[req.URL.Host] = append(sizes[req.URL.Host], len(docs))
+		}
+		Expect(sizes["leader1.foo.bar"]).To(ConsistOf(2, 1))
+		Expect(sizes["leader2.foo.bar"]).To(ConsistOf(1))
+	})
+
+	It("reports the docs of a failed batch", func() {
+		cli.status = http.StatusBadRequest
+		cli.body = `{"responseHeader":{"status":400},"error":{"msg":"unknown field","code":400}}`
+		failed := 0
+		bi := solr.NewBulkIndexer(context.Background(), solrHttp, locator, solr.BulkFlushInterval(0),
+			solr.BulkOnFailure(func(doc map[string]interface{}, err error) {
+				Expect(err).To(MatchError(ContainSubstring("unknown field")))
+				failed++
+			}))
+		Expect(bi.Add(context.Background(), map[string]interface{}{"id": "shard1!a"})).To(BeNil())
+		Expect(bi.Add(context.Background(), map[string]interface{}{"id": "shard1!b"})).To(BeNil())
+		stats, err := bi.Close(context.Background())
+		Expect(err).To(BeNil())
+		Expect(stats.Failed).To(Equal(2))
+		Expect(failed).To(Equal(2))
+	})
+
+	It("rejects docs without an id and docs added after close", func() {
+		bi := solr.NewBulkIndexer(context.Background(), solrHttp, locator)
+		Expect(bi.Add(context.Background(), map[string]interface{}{"name": "no id"})).To(Not(BeNil()))
+		_, err := bi.Close(context.Background())
+		Expect(err).To(BeNil())
+		err = bi.Add(context.Background(), map[string]interface{}{"id": "shard1!a"})
+		Expect(err).To(Equal(solr.ErrBulkIndexerClosed))
+		Expect(cli.requests).To(BeEmpty())
+	})
+})
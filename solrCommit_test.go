@@ -0,0 +1,54 @@
+package solr_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Commit", func() {
+	var cli *fakeHTTPer
+	var retrier solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0,"QTime":12}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		retrier = solr.NewSolrHttpRetrier(solrHttp, 3, 10*time.Millisecond)
+	})
+
+	It("sends a bodyless soft commit", func() {
+		r, err := retrier.Commit(context.Background(), []string{"http://a.foo.bar"}, solr.SoftCommit(true), solr.WaitSearcher(false))
+		Expect(err).To(BeNil())
+		Expect(r.QTime).To(Equal(12))
+		Expect(cli.requests).To(HaveLen(1))
+		Expect(cli.requests[0].URL.Path).To(Equal("/solrtest/update"))
+		Expect(cli.requests[0].ContentLength).To(BeZero())
+		params := cli.requests[0].URL.Query()
+		Expect(params.Get("commit")).To(Equal("true"))
+		Expect(params.Get("softCommit")).To(Equal("true"))
+		Expect(params.Get("waitSearcher")).To(Equal("false"))
+		Expect(params.Get("min_rf")).To(BeEmpty())
+	})
+
+	It("retries an optimize on the next node", func() {
+		cli.statuses = []int{http.StatusServiceUnavailable}
+		cli.bodies = []string{"unavailable"}
+		_, err := retrier.Optimize(context.Background(), []string{"http://a.foo.bar", "http://b.foo.bar"}, solr.MaxSegments(2))
+		Expect(err).To(BeNil())
+		Expect(cli.requests).To(HaveLen(2))
+		Expect(cli.requests[1].URL.Host).To(Equal("b.foo.bar"))
+		Expect(cli.requests[1].URL.Query().Get("optimize")).To(Equal("true"))
+		Expect(cli.requests[1].URL.Query().Get("maxSegments")).To(Equal("2"))
+	})
+
+	It("attaches commitWithin to an update", func() {
+		err := retrier.Update([]string{"http://a.foo.bar"}, true, map[string]interface{}{"id": "1"}, solr.CommitWithin(1500*time.Millisecond), solr.OpenSearcher(false))
+		Expect(err).To(BeNil())
+		Expect(cli.requests[0].URL.Query().Get("commitWithin")).To(Equal("1500"))
+		Expect(cli.requests[0].URL.Query().Get("openSearcher")).To(Equal("false"))
+	})
+})
@@ -0,0 +1,128 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	uniqueKey         = "id"
+	initialCursorMark = "*"
+)
+
+// CursorIterator walks a whole result set with cursorMark deep paging, one page per Next call.
+// When built on a SolrHttpRetrier a failed page is retried on its own instead of restarting the scan
+type CursorIterator struct {
+	cli        SolrHTTPContext
+	nodeUris   []string
+	opts       []func(url.Values)
+	sort       string
+	cursorMark string
+	page       SolrResponse
+	done       bool
+	err        error
+}
+
+// NewCursorIterator returns an iterator over every doc matching opts. The uniqueKey id is appended
+// to the sort as a tiebreaker when it does not end with it, a non zero start is rejected as solr does
+func NewCursorIterator(cli SolrHTTPContext, nodeUris []string, opts ...func(url.Values)) (*CursorIterator, error) {
+	if len(nodeUris) == 0 {
+		return nil, fmt.Errorf("[go-solr] cursor: empty node uris is not valid")
+	}
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	if start := params.Get("start"); start != "" && start != "0" {
+		return nil, fmt.Errorf("[go-solr] cursor: start must be 0 when paging with a cursor, got %s", start)
+	}
+	sort := cursorSort(params.Get("sort"))
+	cursorMark := params.Get("cursorMark")
+	if cursorMark == "" {
+		cursorMark = initialCursorMark
+	}
+	return &CursorIterator{cli: cli, nodeUris: nodeUris, opts: opts, sort: sort, cursorMark: cursorMark}, nil
+}
+
+// cursorSort makes sure the sort ends in a uniqueKey clause so cursor marks are unambiguous. Only the
+// last top level clause is looked at, function sorts like sum(a,b) desc are passed to solr as is
+func cursorSort(sort string) string {
+	if strings.TrimSpace(sort) == "" {
+		return uniqueKey + " asc"
+	}
+	fields := strings.Fields(sort[lastTopLevelComma(sort)+1:])
+	if len(fields) == 2 && fields[0] == uniqueKey && (fields[1] == "asc" || fields[1] == "desc") {
+		return sort
+	}
+	return sort + "," + uniqueKey + " asc"
+}
+
+// lastTopLevelComma is the index of the last comma of sort outside of parens, braces and quotes, or -1
+func lastTopLevelComma(sort string) int {
+	last, depth := -1, 0
+	var quote rune
+	for i, r := range sort {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(' || r == '{' || r == '[':
+			depth++
+		case r == ')' || r == '}' || r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			last = i
+		}
+	}
+	return last
+}
+
+// Next fetches the next page, it returns false once the result set is exhausted or a request failed
+func (c *CursorIterator) Next(ctx context.Context) bool {
+	if c.done || c.err != nil {
+		return false
+	}
+	opts := make([]func(url.Values), 0, len(c.opts)+3)
+	opts = append(opts, c.opts...)
+	opts = append(opts, Sort(c.sort), Cursor(c.cursorMark), Start(0))
+	page, err := c.cli.SelectContext(ctx, c.nodeUris, opts...)
+	if err != nil {
+		c.err = err
+		return false
+	}
+	if page.NextCursorMark == "" {
+		c.err = fmt.Errorf("[go-solr] cursor: response is missing nextCursorMark")
+		return false
+	}
+	c.page = page
+	if page.NextCursorMark == c.cursorMark {
+		c.done = true
+	}
+	c.cursorMark = page.NextCursorMark
+	return len(page.Response.Docs) > 0
+}
+
+// Page returns the response of the page fetched by the last call to Next
+func (c *CursorIterator) Page() SolrResponse {
+	return c.page
+}
+
+// Docs returns the docs of the page fetched by the last call to Next
+func (c *CursorIterator) Docs() []map[string]interface{} {
+	return c.page.Response.Docs
+}
+
+// CursorMark returns the mark of the next page, it can be passed to Cursor to resume a scan later
+func (c *CursorIterator) CursorMark() string {
+	return c.cursorMark
+}
+
+// Err returns the error that stopped the iteration, if any
+func (c *CursorIterator) Err() error {
+	return c.err
+}
@@ -0,0 +1,69 @@
+package solr_test
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Cursor Iterator", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+	})
+
+	It("walks every page until the mark stops changing", func() {
+		cli.bodies = []string{
+			`{"response":{"numFound":3,"docs":[{"id":"1"},{"id":"2"}]},"nextCursorMark":"AoE1"}`,
+			`{"response":{"numFound":3,"docs":[{"id":"3"}]},"nextCursorMark":"AoE2"}`,
+			`{"response":{"numFound":3,"docs":[]},"nextCursorMark":"AoE2"}`,
+		}
+		it, err := solr.NewCursorIterator(solrHttp, []string{"http://a.foo.bar"}, solr.Query("*:*"), solr.Sort("created_at desc"), solr.Rows(2))
+		Expect(err).To(BeNil())
+		var ids []string
+		for it.Next(context.Background()) {
+			for _, doc := range it.Docs() {
+				ids = append(ids, solr.GetDocIdFromDoc(doc))
+			}
+		}
+		Expect(it.Err()).To(BeNil())
+		Expect(ids).To(Equal([]string{"1", "2", "3"}))
+		Expect(cli.requests).To(HaveLen(3))
+		Expect(cli.requests[1].FormValue("cursorMark")).To(Equal("AoE1"))
+		Expect(cli.requests[0].FormValue("sort")).To(Equal("created_at desc,id asc"))
+	})
+
+	It("keeps a sort that already breaks ties on id", func() {
+		cli.body = `{"response":{"numFound":0,"docs":[]},"nextCursorMark":"*"}`
+		it, err := solr.NewCursorIterator(solrHttp, []string{"http://a.foo.bar"}, solr.Sort("id desc"))
+		Expect(err).To(BeNil())
+		Expect(it.Next(context.Background())).To(BeFalse())
+		Expect(it.Err()).To(BeNil())
+		Expect(cli.requests[0].FormValue("sort")).To(Equal("id desc"))
+	})
+
+	It("appends the id to a function sort", func() {
+		cli.body = `{"response":{"numFound":0,"docs":[]},"nextCursorMark":"*"}`
+		it, err := solr.NewCursorIterator(solrHttp, []string{"http://a.foo.bar"}, solr.Sort("sum(a,b) desc, query({!v='x:y,z'}) desc"))
+		Expect(err).To(BeNil())
+		Expect(it.Next(context.Background())).To(BeFalse())
+		Expect(cli.requests[0].FormValue("sort")).To(Equal("sum(a,b) desc, query({!v='x:y,z'}) desc,id asc"))
+
+		it, err = solr.NewCursorIterator(solrHttp, []string{"http://a.foo.bar"}, solr.Sort("sum(a,b) desc, id desc"))
+		Expect(err).To(BeNil())
+		Expect(it.Next(context.Background())).To(BeFalse())
+		Expect(cli.requests[1].FormValue("sort")).To(Equal("sum(a,b) desc, id desc"))
+	})
+
+	It("rejects a non zero start", func() {
+		_, err := solr.NewCursorIterator(solrHttp, []string{"http://a.foo.bar"}, solr.Start(10))
+		Expect(err).To(Not(BeNil()))
+	})
+})
@@ -0,0 +1,279 @@
+package solr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// DecodeDocs decodes solr docs into dst, a pointer to a slice of structs or struct pointers.
+// Struct fields are matched with the `solr:"field"` tag, or the field name when untagged,
+// `solr:"-"` skips a field. Multi-valued fields decode into slices, dates into time.Time
+// and fields missing from a doc keep their zero value
+func DecodeDocs(docs []map[string]interface{}, dst interface{}) error {
+	slice, elemType, err := docsSlice(dst)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		elem := reflect.New(elemType).Elem()
+		if err := decodeDocInto(doc, elem, elemType); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	reflect.ValueOf(dst).Elem().Set(slice)
+	return nil
+}
+
+// DecodeDoc decodes a single solr doc into dst, a pointer to a struct
+func DecodeDoc(doc map[string]interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("[go-solr] decode: dst must be a non nil pointer to a struct, got %T", dst)
+	}
+	return decodeStruct(doc, v.Elem())
+}
+
+// docsSlice returns an empty slice of the type dst points to and the type of its elements
+func docsSlice(dst interface{}) (reflect.Value, reflect.Type, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, nil, fmt.Errorf("[go-solr] decode: dst must be a non nil pointer to a slice, got %T", dst)
+	}
+	sliceType := v.Elem().Type()
+	elemType := sliceType.Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("[go-solr] decode: dst must be a slice of structs, got %T", dst)
+	}
+	return reflect.MakeSlice(sliceType, 0, 0), elemType, nil
+}
+
+func decodeDocInto(doc map[string]interface{}, elem reflect.Value, elemType reflect.Type) error {
+	if elemType.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(elemType.Elem()))
+		elem = elem.Elem()
+	}
+	return decodeStruct(doc, elem)
+}
+
+type solrField struct {
+	name  string
+	index []int
+}
+
+var structFieldsCache = struct {
+	sync.RWMutex
+	fields map[reflect.Type][]solrField
+}{fields: make(map[reflect.Type][]solrField)}
+
+// structFields returns the solr field name for every settable field of t
+func structFields(t reflect.Type) []solrField {
+	structFieldsCache.RLock()
+	fields, ok := structFieldsCache.fields[t]
+	structFieldsCache.RUnlock()
+	if ok {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("solr"); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, solrField{name: name, index: f.Index})
+	}
+	structFieldsCache.Lock()
+	structFieldsCache.fields[t] = fields
+	structFieldsCache.Unlock()
+	return fields
+}
+
+func decodeStruct(doc map[string]interface{}, v reflect.Value) error {
+	for _, f := range structFields(v.Type()) {
+		value, ok := doc[f.name]
+		if !ok || value == nil {
+			continue
+		}
+		if err := decodeValue(value, v.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf("[go-solr] decode field %s: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+func decodeValue(value interface{}, field reflect.Value) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := decodeValue(value, ptr.Elem()); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Kind() == reflect.Interface {
+		field.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	values, multiValued := value.([]interface{})
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		if !multiValued {
+			values = []interface{}{value}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := decodeValue(v, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	if multiValued {
+		if len(values) != 1 {
+			return fmt.Errorf("cannot decode %d values into %s", len(values), field.Type())
+		}
+		return decodeValue(values[0], field)
+	}
+
+	if field.Type() == timeType {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into time.Time", value)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into string", value)
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into bool", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(i) {
+			return fmt.Errorf("%d overflows %s", i, field.Type())
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := toInt64(value)
+		if err != nil {
+			return err
+		}
+		if i < 0 || field.OverflowUint(uint64(i)) {
+			return fmt.Errorf("%d overflows %s", i, field.Type())
+		}
+		field.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		// []byte, solr returns binary fields base64 encoded
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into []byte", value)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		field.SetBytes(b)
+	case reflect.Struct:
+		// nested child documents
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", value, field.Type())
+		}
+		return decodeStruct(m, field)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return floatToInt64(f)
+	case float64:
+		return floatToInt64(v)
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("cannot decode %T into an integer", value)
+}
+
+func floatToInt64(f float64) (int64, error) {
+	if f != float64(int64(f)) {
+		return 0, fmt.Errorf("cannot decode %v into an integer", f)
+	}
+	return int64(f), nil
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("cannot decode %T into a float", value)
+}
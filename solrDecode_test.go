@@ -0,0 +1,68 @@
+package solr_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+type contact struct {
+	ID        string    `solr:"id"`
+	Version   int64     `solr:"_version_"`
+	Emails    []string  `solr:"email"`
+	FirstName string    `solr:"first_name"`
+	Score     *float64  `solr:"score"`
+	Created   time.Time `solr:"created_at"`
+	Ignored   string    `solr:"-"`
+}
+
+var _ = Describe("Decode Docs", func() {
+	It("decodes tagged fields", func() {
+		docs := []map[string]interface{}{
+			{"id": "a!1", "email": []interface{}{"a@b.com", "c@d.com"}, "first_name": []interface{}{"shawn"}, "created_at": "2018-06-01T10:11:12.5Z", "score": 1.5},
+			{"id": "a!2", "email": "e@f.com", "-": "x"},
+		}
+		var contacts []contact
+		err := solr.DecodeDocs(docs, &contacts)
+		Expect(err).To(BeNil())
+		Expect(contacts).To(HaveLen(2))
+		Expect(contacts[0].ID).To(Equal("a!1"))
+		Expect(contacts[0].Emails).To(Equal([]string{"a@b.com", "c@d.com"}))
+		Expect(contacts[0].FirstName).To(Equal("shawn"))
+		Expect(*contacts[0].Score).To(Equal(1.5))
+		Expect(contacts[0].Created).To(Equal(time.Date(2018, 6, 1, 10, 11, 12, 500000000, time.UTC)))
+		Expect(contacts[1].Emails).To(Equal([]string{"e@f.com"}))
+		Expect(contacts[1].Score).To(BeNil())
+		Expect(contacts[1].Created.IsZero()).To(BeTrue())
+		Expect(contacts[1].Ignored).To(BeEmpty())
+	})
+
+	It("rejects several values for a single valued field", func() {
+		var contacts []contact
+		err := solr.DecodeDocs([]map[string]interface{}{{"id": []interface{}{"a", "b"}}}, &contacts)
+		Expect(err).To(Not(BeNil()))
+	})
+
+	It("rejects a dst that is not a slice of structs", func() {
+		var ids []string
+		Expect(solr.DecodeDocs(nil, &ids)).To(Not(BeNil()))
+		Expect(solr.DecodeDocs(nil, ids)).To(Not(BeNil()))
+	})
+
+	It("selects into structs keeping the exact version", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0},"response":{"numFound":1,"start":0,"docs":[{"id":"a!1","_version_":1603386011406549000}]}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		var contacts []*contact
+		r, err := solrHttp.SelectInto(context.Background(), []string{"http://a.foo.bar"}, &contacts, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		Expect(r.Response.NumFound).To(BeEquivalentTo(1))
+		Expect(r.Response.Docs).To(BeEmpty())
+		Expect(contacts).To(HaveLen(1))
+		Expect(contacts[0].Version).To(Equal(int64(1603386011406549000)))
+	})
+})
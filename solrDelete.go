@@ -0,0 +1,96 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DeleteResult is the outcome of the delete request sent to one shard, UpdateResult carries
+// its rf, min_rf and QTime and the versions of the deleted ids when Versions is set
+type DeleteResult struct {
+	Shard string
+	IDs   []string
+	UpdateResult
+	Err error
+}
+
+type deleteQueryRequest struct {
+	Delete struct {
+		Query string `json:"query"`
+	} `json:"delete"`
+}
+
+// DeleteByID deletes docs by id with a json delete command. The ids are grouped by the shard their
+// composite id hashes to and each group is sent to its leader and replicas through cli.UpdateContext,
+// so min_rf is checked and a SolrHttpRetrier retries each group. Pass Route for collections whose docs
+// are routed on a _route_ other than their id, every id is then sent to the shard of the route.
+// One result is returned per shard
+func DeleteByID(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, ids []string, opts ...func(url.Values)) ([]DeleteResult, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	route := params.Get("_route_")
+	var groups map[string][]string
+	if route != "" {
+		shard, err := locator.GetShardFromRoute(route)
+		if err != nil {
+			return nil, err
+		}
+		groups = map[string][]string{shard: ids}
+		// the locator hashes keys as composite ids, a route is the prefix of one
+		if strings.LastIndex(route, "!") != len(route)-1 {
+			route += "!"
+		}
+	} else {
+		var err error
+		if groups, err = groupByShard(locator, ids); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]DeleteResult, 0, len(groups))
+	groupUris := make([][]string, 0, len(groups))
+	for shard, group := range groups {
+		key := group[0]
+		if route != "" {
+			key = route
+		}
+		nodeUris, err := locator.GetLeadersAndReplicas(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(nodeUris) == 0 {
+			return nil, NewSolrLeaderError(key)
+		}
+		results = append(results, DeleteResult{Shard: shard, IDs: group})
+		groupUris = append(groupUris, nodeUris)
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(result *DeleteResult, nodeUris []string) {
+			defer wg.Done()
+			result.UpdateResult, result.Err = cli.UpdateContext(ctx, nodeUris, false, DeleteRequest{Delete: result.IDs}, opts...)
+		}(&results[i], groupUris[i])
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// DeleteByQuery deletes every doc matching query with a json delete command, solr distributes it to
+// every shard of the collection so nodeUris can be any nodes hosting it
+func DeleteByQuery(ctx context.Context, cli SolrHTTPContext, nodeUris []string, query string, opts ...func(url.Values)) (UpdateResult, error) {
+	if query == "" {
+		return UpdateResult{}, fmt.Errorf("[go-solr] delete by query: empty query is not valid")
+	}
+	var req deleteQueryRequest
+	req.Delete.Query = query
+	return cli.UpdateContext(ctx, nodeUris, false, req, opts...)
+}
@@ -0,0 +1,90 @@
+package solr_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Delete", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0,"rf":2,"min_rf":2}}`}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.MinRF(2))
+		Expect(err).To(BeNil())
+	})
+
+	It("deletes ids grouped by shard leader", func() {
+		locator := &fakeLocator{shardNodes: map[string][]string{
+			"shard1": {"http://leader1.foo.bar"},
+			"shard2": {"http://leader2.foo.bar"},
+		}}
+		results, err := solr.DeleteByID(context.Background(), solrHttp, locator, []string{"shard1!a", "shard2!b", "shard1!c"})
+		Expect(err).To(BeNil())
+		Expect(results).To(HaveLen(2))
+		for _, result := range results {
+			Expect(result.Err).To(BeNil())
+			Expect(result.RF).To(Equal(2))
+		}
+		var bodies []string
+		for _, req := range cli.requests {
+			Expect(req.URL.Query().Get("min_rf")).To(Equal("2"))
+			body, _ := ioutil.ReadAll(req.Body)
+			bodies = append(bodies, req.URL.Host+" "+strings.TrimSpace(string(body)))
+		}
+		sort.Strings(bodies)
+		Expect(bodies).To(Equal([]string{`leader1.foo.bar {"delete":["shard1!a","shard1!c"]}`, `leader2.foo.bar {"delete":["shard2!b"]}`}))
+	})
+
+	It("sends every id to the shard of the route", func() {
+		locator := &fakeLocator{shardNodes: map[string][]string{
+			"shard1": {"http://leader1.foo.bar"},
+			"shard2": {"http://leader2.foo.bar"},
+		}}
+		results, err := solr.DeleteByID(context.Background(), solrHttp, locator, []string{"a", "shard2!b"}, solr.Route("shard1"))
+		Expect(err).To(BeNil())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Shard).To(Equal("shard1"))
+		Expect(results[0].IDs).To(Equal([]string{"a", "shard2!b"}))
+		Expect(cli.requests).To(HaveLen(1))
+		Expect(cli.requests[0].URL.Host).To(Equal("leader1.foo.bar"))
+		Expect(cli.requests[0].URL.Query().Get("_route_")).To(Equal("shard1"))
+	})
+
+	It("reports a shard that misses min_rf", func() {
+		cli.body = `{"responseHeader":{"status":0,"rf":1,"min_rf":2}}`
+		locator := &fakeLocator{shardNodes: map[string][]string{"shard1": {"http://leader1.foo.bar"}}}
+		results, err := solr.DeleteByID(context.Background(), solrHttp, locator, []string{"shard1!a"})
+		Expect(err).To(BeNil())
+		_, ok := results[0].Err.(solr.SolrMinRFError)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("returns the versions of the deleted ids", func() {
+		cli.body = `{"responseHeader":{"status":0,"rf":2,"min_rf":2},"deletes":["shard1!a",-21]}`
+		locator := &fakeLocator{shardNodes: map[string][]string{"shard1": {"http://leader1.foo.bar"}}}
+		results, err := solr.DeleteByID(context.Background(), solrHttp, locator, []string{"shard1!a"}, solr.Versions(true))
+		Expect(err).To(BeNil())
+		Expect(results[0].Err).To(BeNil())
+		Expect(results[0].Deletes).To(Equal(solr.DocVersions{"shard1!a": -21}))
+		Expect(cli.requests[0].URL.Query().Get("versions")).To(Equal("true"))
+	})
+
+	It("deletes by query with a json body", func() {
+		r, err := solr.DeleteByQuery(context.Background(), solrHttp, []string{"http://a.foo.bar"}, `last_name:"o'neil" AND age:[* TO 5]`)
+		Expect(err).To(BeNil())
+		Expect(r.RF).To(Equal(2))
+		Expect(r.MinRF).To(Equal(2))
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		Expect(string(body)).To(MatchJSON(`{"delete":{"query":"last_name:\"o'neil\" AND age:[* TO 5]"}}`))
+		Expect(cli.requests[0].URL.Path).To(Equal("/solrtest/update"))
+	})
+})
@@ -0,0 +1,44 @@
+package solr
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// ShardExport is the outcome of exporting one shard
+type ShardExport struct {
+	Shard string
+	ExportResponse
+	Err error
+}
+
+// ExportCollection fans the export out to one replica of every shard found by the locator and streams
+// the docs of all shards to fn. The shards are read concurrently but fn is never called concurrently.
+// Each shard reports whether its stream reached EOF and the error that stopped it, if any
+func ExportCollection(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, fn func(shard string, doc map[string]interface{}) error, opts ...func(url.Values)) ([]ShardExport, error) {
+	shardCores, err := getShardCores(locator)
+	if err != nil {
+		return nil, err
+	}
+	exports := make([]ShardExport, 0, len(shardCores))
+	for shard := range shardCores {
+		exports = append(exports, ShardExport{Shard: shard})
+	}
+
+	var fnLock sync.Mutex
+	var wg sync.WaitGroup
+	for i := range exports {
+		wg.Add(1)
+		go func(export *ShardExport) {
+			defer wg.Done()
+			export.ExportResponse, export.Err = cli.Export(ctx, shardCores[export.Shard], func(doc map[string]interface{}) error {
+				fnLock.Lock()
+				defer fnLock.Unlock()
+				return fn(export.Shard, doc)
+			}, opts...)
+		}(&exports[i])
+	}
+	wg.Wait()
+	return exports, nil
+}
@@ -0,0 +1,112 @@
+package solr_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+// fakeLocator serves a fixed cluster layout, docs are routed to the shard named by their shard key
+type fakeLocator struct {
+	shardCores  map[string][]string
+	shardNodes  map[string][]string
+	replicaUris []string
+}
+
+func (l *fakeLocator) GetLeaders(docID string) ([]string, error) {
+	return l.shardNodes[shardKey(docID)][:1], nil
+}
+func (l *fakeLocator) GetReplicaUris() ([]string, error)                   { return l.replicaUris, nil }
+func (l *fakeLocator) GetReplicasFromRoute(route string) ([]string, error) { return nil, nil }
+func (l *fakeLocator) GetShardFromRoute(route string) (string, error)      { return shardKey(route), nil }
+func (l *fakeLocator) GetLeadersAndReplicas(docID string) ([]string, error) {
+	return l.shardNodes[shardKey(docID)], nil
+}
+func (l *fakeLocator) GetShardCores() (map[string][]string, error) { return l.shardCores, nil }
+func (l *fakeLocator) GroupByShard(docIDs []string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, id := range docIDs {
+		groups[shardKey(id)] = append(groups[shardKey(id)], id)
+	}
+	return groups, nil
+}
+
+func shardKey(docID string) string {
+	return strings.Split(docID, "!")[0]
+}
+
+var _ = Describe("Export", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+	})
+
+	It("requires fields and sort", func() {
+		_, err := solrHttp.Export(context.Background(), []string{"http://a.foo.bar/solr/core1"}, func(doc map[string]interface{}) error { return nil }, solr.Query("*:*"))
+		Expect(err).To(Not(BeNil()))
+		Expect(cli.requests).To(BeEmpty())
+	})
+
+	It("streams a shard to its EOF marker", func() {
+		cli.body = `{"responseHeader":{"status":0},"response":{"numFound":2,"docs":[{"id":"1"},{"id":"2"},{"EOF":true}]}}`
+		var ids []string
+		r, err := solrHttp.Export(context.Background(), []string{"http://a.foo.bar/solr/core1"}, func(doc map[string]interface{}) error {
+			ids = append(ids, solr.GetDocIdFromDoc(doc))
+			return nil
+		}, solr.Query("*:*"), solr.Fields("id"), solr.Sort("id asc"))
+		Expect(err).To(BeNil())
+		Expect(ids).To(Equal([]string{"1", "2"}))
+		Expect(r.EOF).To(BeTrue())
+		Expect(r.NumFound).To(BeEquivalentTo(2))
+		Expect(r.Exported).To(Equal(2))
+		Expect(cli.requests[0].URL.String()).To(Equal("http://a.foo.bar/solr/core1/export"))
+	})
+
+	It("reports the exception marker", func() {
+		cli.body = `{"responseHeader":{"status":0},"response":{"numFound":2,"docs":[{"id":"1"},{"EXCEPTION":"field not docValues","EOF":true}]}}`
+		r, err := solrHttp.Export(context.Background(), []string{"http://a.foo.bar/solr/core1"}, func(doc map[string]interface{}) error { return nil }, solr.Fields("id"), solr.Sort("id asc"))
+		_, ok := err.(solr.SolrExportError)
+		Expect(ok).To(BeTrue())
+		Expect(r.EOF).To(BeTrue())
+		Expect(r.Exported).To(Equal(1))
+	})
+
+	It("exports every shard of the collection", func() {
+		cli.body = `{"response":{"numFound":1,"docs":[{"id":"1"}]}}`
+		locator := &fakeLocator{shardCores: map[string][]string{
+			"shard1": {"http://a.foo.bar/solr/core1"},
+			"shard2": {"http://b.foo.bar/solr/core2"},
+		}}
+		var shards []string
+		exports, err := solr.ExportCollection(context.Background(), solrHttp, locator, func(shard string, doc map[string]interface{}) error {
+			shards = append(shards, shard)
+			return nil
+		}, solr.Fields("id"), solr.Sort("id asc"))
+		Expect(err).To(BeNil())
+		Expect(exports).To(HaveLen(2))
+		for _, export := range exports {
+			Expect(export.Err).To(BeNil())
+			Expect(export.EOF).To(BeTrue())
+		}
+		sort.Strings(shards)
+		Expect(shards).To(Equal([]string{"shard1", "shard2"}))
+	})
+
+	It("needs a locator that knows the shards", func() {
+		locator := struct{ solr.SolrLocator }{&fakeLocator{}}
+		_, err := solr.ExportCollection(context.Background(), solrHttp, locator, func(shard string, doc map[string]interface{}) error {
+			return nil
+		}, solr.Fields("id"), solr.Sort("id asc"))
+		Expect(err).NotTo(BeNil())
+		Expect(cli.requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,164 @@
+package solr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FacetCounts is the facet_counts section of a response, keyed by field, query or the key local param
+type FacetCounts struct {
+	Queries map[string]int          `json:"facet_queries"`
+	Fields  map[string]TermCounts   `json:"facet_fields"`
+	Ranges  map[string]RangeFacet   `json:"facet_ranges"`
+	Pivots  map[string][]PivotFacet `json:"facet_pivot"`
+}
+
+// TermCount is the count of one term of a field or one bucket of a range
+type TermCount struct {
+	Term  string
+	Count int
+}
+
+// TermCounts are term counts in the order solr sorted them, solr sends them as a flat
+// [term, count, ...] list and the missing bucket with a null term, or as an object with json.nl=map
+type TermCounts []TermCount
+
+func (t *TermCounts) UnmarshalJSON(b []byte) error {
+	entries, err := rawNamedEntries(b)
+	if err != nil {
+		return err
+	}
+	counts := make(TermCounts, 0, len(entries))
+	for _, entry := range entries {
+		var count json.Number
+		if err := json.Unmarshal(entry.value, &count); err != nil {
+			return fmt.Errorf("[go-solr] facet: %s is not a term count", entry.value)
+		}
+		n, err := count.Int64()
+		if err != nil {
+			return err
+		}
+		counts = append(counts, TermCount{Term: entry.name, Count: int(n)})
+	}
+	*t = counts
+	return nil
+}
+
+// Map returns the counts by term, losing their order
+func (t TermCounts) Map() map[string]int {
+	m := make(map[string]int, len(t))
+	for _, c := range t {
+		m[c.Term] = c.Count
+	}
+	return m
+}
+
+// RangeFacet is the buckets of a range facet, Before, After and Between are only set when
+// asked for with facet.range.other. Start, End and Gap are numbers or date strings
+type RangeFacet struct {
+	Counts  TermCounts  `json:"counts"`
+	Start   interface{} `json:"start"`
+	End     interface{} `json:"end"`
+	Gap     interface{} `json:"gap"`
+	Before  int         `json:"before"`
+	After   int         `json:"after"`
+	Between int         `json:"between"`
+}
+
+// PivotFacet is one value of a pivot field and the pivots of the next field under it.
+// Stats are the stats of the stats fields tagged by the stats local param of the pivot
+type PivotFacet struct {
+	Field string       `json:"field"`
+	Value interface{}  `json:"value"`
+	Count int          `json:"count"`
+	Pivot []PivotFacet `json:"pivot"`
+	Stats *Stats       `json:"stats"`
+}
+
+// localParams prefixes value with local params like ex=tag or key=label
+func localParams(value string, params []string) string {
+	if len(params) == 0 {
+		return value
+	}
+	return "{!" + strings.Join(params, " ") + "}" + value
+}
+
+// FacetField adds a field facet, localParams like "ex=tag" or "key=label" are prefixed to the field
+func FacetField(field string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet", "true")
+		p.Add("facet.field", localParams(field, localParam))
+	}
+}
+
+// FacetQuery adds a facet counting the docs matching q
+func FacetQuery(q string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet", "true")
+		p.Add("facet.query", localParams(q, localParam))
+	}
+}
+
+// FacetRange adds a range facet over field from start to end in steps of gap, dates take date math like +1DAY
+func FacetRange(field string, start string, end string, gap string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet", "true")
+		p.Add("facet.range", localParams(field, localParam))
+		p.Set("f."+field+".facet.range.start", start)
+		p.Set("f."+field+".facet.range.end", end)
+		p.Set("f."+field+".facet.range.gap", gap)
+	}
+}
+
+// FacetPivot adds a pivot facet over the fields, counted under each other in order
+func FacetPivot(fields []string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet", "true")
+		p.Add("facet.pivot", localParams(strings.Join(fields, ","), localParam))
+	}
+}
+
+// FacetFieldParam sets a facet param for one field only, like FacetFieldParam("cat", "limit", "10")
+func FacetFieldParam(field string, param string, value string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("f."+field+".facet."+param, value)
+	}
+}
+
+// FacetLimit sets the number of terms returned per field facet, -1 returns them all
+func FacetLimit(limit int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet.limit", strconv.Itoa(limit))
+	}
+}
+
+// FacetMinCount drops the terms counted less than minCount
+func FacetMinCount(minCount int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet.mincount", strconv.Itoa(minCount))
+	}
+}
+
+// FacetSort sorts the terms by count or index
+func FacetSort(sort string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet.sort", sort)
+	}
+}
+
+// FacetMissing adds a bucket with a null term counting the docs without a value
+func FacetMissing(missing bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("facet.missing", strconv.FormatBool(missing))
+	}
+}
+
+// FacetRangeOther asks for the before, after and between counts of the range facets, "all" for every one
+func FacetRangeOther(other ...string) func(url.Values) {
+	return func(p url.Values) {
+		p["facet.range.other"] = other
+	}
+}
@@ -0,0 +1,78 @@
+package solr_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Facets", func() {
+	It("builds the facet params", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"),
+			solr.FacetField("cat", "ex=dt", "key=categories"), solr.FacetField("inStock"),
+			solr.FacetQuery("price:[0 TO 10]"),
+			solr.FacetRange("price", "0", "1000", "100"), solr.FacetRangeOther("all"),
+			solr.FacetPivot([]string{"cat", "inStock"}),
+			solr.FacetFieldParam("cat", "limit", "5"), solr.FacetMinCount(1))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("facet")).To(Equal("true"))
+		Expect(params["facet.field"]).To(Equal([]string{"{!ex=dt key=categories}cat", "inStock"}))
+		Expect(params.Get("facet.query")).To(Equal("price:[0 TO 10]"))
+		Expect(params.Get("facet.range")).To(Equal("price"))
+		Expect(params.Get("f.price.facet.range.gap")).To(Equal("100"))
+		Expect(params.Get("facet.range.other")).To(Equal("all"))
+		Expect(params.Get("facet.pivot")).To(Equal("cat,inStock"))
+		Expect(params.Get("f.cat.facet.limit")).To(Equal("5"))
+		Expect(params.Get("facet.mincount")).To(Equal("1"))
+	})
+
+	It("decodes facet counts in order", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"response":{"numFound":0,"start":0,"docs":[]},
+			"facet_counts":{
+				"facet_queries":{"price:[0 TO 10]":3},
+				"facet_fields":{"categories":["memory",14,"electronics",3,null,1]},
+				"facet_ranges":{"price":{"counts":["0.0",3,"100.0",6],"gap":100.0,"start":0.0,"end":1000.0,"before":0,"after":2,"between":9}},
+				"facet_pivot":{"cat,inStock":[{"field":"cat","value":"memory","count":14,"pivot":[{"field":"inStock","value":true,"count":10}]}]}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		facets := r.FacetCounts
+		Expect(facets.Queries["price:[0 TO 10]"]).To(Equal(3))
+		Expect(facets.Fields["categories"]).To(Equal(solr.TermCounts{{Term: "memory", Count: 14}, {Term: "electronics", Count: 3}, {Term: "", Count: 1}}))
+		Expect(facets.Ranges["price"].Counts.Map()).To(Equal(map[string]int{"0.0": 3, "100.0": 6}))
+		Expect(facets.Ranges["price"].Between).To(Equal(9))
+		Expect(facets.Ranges["price"].Gap).To(Equal(100.0))
+		pivot := facets.Pivots["cat,inStock"][0]
+		Expect(pivot.Value).To(Equal("memory"))
+		Expect(pivot.Pivot[0].Value).To(Equal(true))
+		Expect(pivot.Pivot[0].Count).To(Equal(10))
+	})
+
+	It("decodes facet counts sent as a map", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"response":{"numFound":0,"start":0,"docs":[]},
+			"facet_counts":{
+				"facet_fields":{"cat":{"memory":14,"electronics":3}},
+				"facet_ranges":{"price":{"counts":{"0.0":3,"100.0":6},"gap":100.0,"start":0.0,"end":1000.0}}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"), solr.FacetField("cat"),
+			solr.UrlVals(url.Values{"json.nl": {"map"}}))
+		Expect(err).To(BeNil())
+		Expect(cli.requests[0].FormValue("json.nl")).To(Equal("map"))
+		Expect(r.FacetCounts.Fields["cat"]).To(Equal(solr.TermCounts{{Term: "memory", Count: 14}, {Term: "electronics", Count: 3}}))
+		Expect(r.FacetCounts.Ranges["price"].Counts.Map()).To(Equal(map[string]int{"0.0": 3, "100.0": 6}))
+	})
+})
@@ -0,0 +1,73 @@
+package solr
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// maxGetIds caps the number of ids sent in a single real-time get request
+const maxGetIds = 500
+
+// GetResult is the outcome of a real-time get for one id, Err is a DocNotFoundError when the doc does not exist
+type GetResult struct {
+	ID  string
+	Doc map[string]interface{}
+	Err error
+}
+
+// Get fetches docs by id with real-time get, reading updates that are not committed yet. The ids are grouped
+// by the shard their composite id hashes to and each group is sent to a live replica of that shard, leader first.
+// The results are returned in the order of ids
+func Get(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, ids []string, opts ...func(url.Values)) ([]GetResult, error) {
+	groups, err := groupByShard(locator, ids)
+	if err != nil {
+		return nil, err
+	}
+	groupUris := make(map[string][]string, len(groups))
+	for shard, group := range groups {
+		if groupUris[shard], err = locator.GetLeadersAndReplicas(group[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	var lock sync.Mutex
+	docs := make(map[string]map[string]interface{}, len(ids))
+	errs := make(map[string]error)
+	var wg sync.WaitGroup
+	for shard, group := range groups {
+		nodeUris := groupUris[shard]
+		for _, chunk := range getidChunks(group, maxGetIds) {
+			wg.Add(1)
+			go func(chunk []string) {
+				defer wg.Done()
+				r, err := cli.RealTimeGet(ctx, nodeUris, chunk, opts...)
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					for _, id := range chunk {
+						errs[id] = err
+					}
+					return
+				}
+				for _, doc := range r.Response.Docs {
+					docs[GetDocIdFromDoc(doc)] = doc
+				}
+			}(chunk)
+		}
+	}
+	wg.Wait()
+
+	results := make([]GetResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		if err, ok := errs[id]; ok {
+			results[i].Err = err
+		} else if doc, ok := docs[id]; ok {
+			results[i].Doc = doc
+		} else {
+			results[i].Err = DocNotFoundError{ID: id}
+		}
+	}
+	return results, nil
+}
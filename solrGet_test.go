@@ -0,0 +1,43 @@
+package solr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Real Time Get", func() {
+	It("sends each id to the leader of its shard and reports missing ids", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{"response":{"numFound":2,"start":0,"docs":[{"id":"shard1!a"},{"id":"shard2!c"}]}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		locator := &fakeLocator{shardNodes: map[string][]string{
+			"shard1": {"http://leader1.foo.bar", "http://replica1.foo.bar"},
+			"shard2": {"http://leader2.foo.bar", "http://replica2.foo.bar"},
+		}}
+		results, err := solr.Get(context.Background(), solrHttp, locator, []string{"shard1!a", "shard1!b", "shard2!c"})
+		Expect(err).To(BeNil())
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].ID).To(Equal("shard1!a"))
+		Expect(results[0].Err).To(BeNil())
+		Expect(solr.GetDocIdFromDoc(results[0].Doc)).To(Equal("shard1!a"))
+		Expect(errors.Is(results[1].Err, solr.ErrNotFound)).To(BeTrue())
+		Expect(results[1].Err).To(Equal(solr.DocNotFoundError{ID: "shard1!b"}))
+		Expect(results[2].Err).To(BeNil())
+
+		var urls, ids []string
+		for _, req := range cli.requests {
+			urls = append(urls, req.URL.String())
+			ids = append(ids, req.FormValue("ids"))
+		}
+		sort.Strings(urls)
+		sort.Strings(ids)
+		Expect(urls).To(Equal([]string{"http://leader1.foo.bar/solrtest/get", "http://leader2.foo.bar/solrtest/get"}))
+		Expect(ids).To(Equal([]string{"shard1!a,shard1!b", "shard2!c"}))
+	})
+})
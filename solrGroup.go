@@ -0,0 +1,100 @@
+package solr
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Grouped is the grouped section of a response, keyed by group.field or group.query
+type Grouped map[string]GroupResult
+
+// GroupResult is the result of one group.field or group.query. Matches counts the docs matching the
+// query, NGroups the groups when group.ngroups is set. A group.field has Groups, a group.query and
+// group.format=simple have DocList instead
+type GroupResult struct {
+	Matches int       `json:"matches"`
+	NGroups int       `json:"ngroups"`
+	Groups  []Group   `json:"groups"`
+	DocList *Response `json:"doclist"`
+}
+
+// Group is the top docs of one value of the group field, GroupValue is nil for the docs without a value
+type Group struct {
+	GroupValue interface{} `json:"groupValue"`
+	DocList    Response    `json:"doclist"`
+}
+
+// Expanded is the expanded section of a collapsed response, the docs collapsed under each
+// collapse value without the head doc returned in the response
+type Expanded map[string]Response
+
+// GroupField groups the results by the values of field, every GroupField adds a grouping
+func GroupField(field string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group", "true")
+		p.Add("group.field", field)
+	}
+}
+
+// GroupQuery adds a group of the docs matching q
+func GroupQuery(q string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group", "true")
+		p.Add("group.query", q)
+	}
+}
+
+// GroupLimit sets the number of docs returned per group, 1 by default
+func GroupLimit(limit int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group.limit", strconv.Itoa(limit))
+	}
+}
+
+// GroupOffset skips the first docs of every group
+func GroupOffset(offset int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group.offset", strconv.Itoa(offset))
+	}
+}
+
+// GroupSort sorts the docs inside every group, Sort orders the groups themselves
+func GroupSort(sort string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group.sort", sort)
+	}
+}
+
+// GroupNGroups counts the groups matching the query into NGroups
+func GroupNGroups(ngroups bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group.ngroups", strconv.FormatBool(ngroups))
+	}
+}
+
+// GroupMain returns the docs of the first grouping as a plain response instead of the grouped section
+func GroupMain(main bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("group.main", strconv.FormatBool(main))
+	}
+}
+
+// Collapse keeps one doc per value of field through a collapse filter, localParams like "min=price"
+// or "nullPolicy=expand" choose the head doc and what happens to docs without a value
+func Collapse(field string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Add("fq", localParams("", append([]string{"collapse", "field=" + field}, localParam...)))
+	}
+}
+
+// Expand returns the docs collapsed under each head doc in the expanded section, at most rows per
+// collapse value sorted by sort. Zero rows only counts them and an empty sort keeps the score order
+func Expand(rows int, sort string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("expand", "true")
+		p.Set("expand.rows", strconv.Itoa(rows))
+		if sort != "" {
+			p.Set("expand.sort", sort)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package solr_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Grouping", func() {
+	It("builds the group and collapse params", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"),
+			solr.GroupField("customer"), solr.GroupQuery("price:[0 TO 10]"), solr.GroupLimit(3),
+			solr.GroupNGroups(true), solr.GroupSort("date desc"),
+			solr.FilterQuery("inStock:true"), solr.Collapse("customer", "max=date"), solr.Expand(2, "date desc"))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("group")).To(Equal("true"))
+		Expect(params.Get("group.field")).To(Equal("customer"))
+		Expect(params.Get("group.query")).To(Equal("price:[0 TO 10]"))
+		Expect(params.Get("group.limit")).To(Equal("3"))
+		Expect(params.Get("group.ngroups")).To(Equal("true"))
+		Expect(params["fq"]).To(Equal([]string{"inStock:true", "{!collapse field=customer max=date}"}))
+		Expect(params.Get("expand")).To(Equal("true"))
+		Expect(params.Get("expand.rows")).To(Equal("2"))
+		Expect(params.Get("expand.sort")).To(Equal("date desc"))
+	})
+
+	It("decodes the grouped section", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"grouped":{
+				"customer":{"matches":5,"ngroups":2,"groups":[
+					{"groupValue":"acme","doclist":{"numFound":3,"start":0,"docs":[{"id":"1"},{"id":"2"}]}},
+					{"groupValue":null,"doclist":{"numFound":2,"start":0,"docs":[{"id":"4"}]}}]},
+				"price:[0 TO 10]":{"matches":5,"doclist":{"numFound":1,"start":0,"docs":[{"id":"3"}]}}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"), solr.GroupField("customer"))
+		Expect(err).To(BeNil())
+		byCustomer := r.Grouped["customer"]
+		Expect(byCustomer.Matches).To(Equal(5))
+		Expect(byCustomer.NGroups).To(Equal(2))
+		Expect(byCustomer.Groups).To(HaveLen(2))
+		Expect(byCustomer.Groups[0].GroupValue).To(Equal("acme"))
+		Expect(byCustomer.Groups[0].DocList.NumFound).To(Equal(uint32(3)))
+		Expect(byCustomer.Groups[0].DocList.Docs[1]["id"]).To(Equal("2"))
+		Expect(byCustomer.Groups[1].GroupValue).To(BeNil())
+		Expect(r.Grouped["price:[0 TO 10]"].DocList.Docs[0]["id"]).To(Equal("3"))
+	})
+
+	It("decodes the expanded section", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"response":{"numFound":2,"start":0,"docs":[{"id":"1","customer":"acme"},{"id":"5","customer":"initech"}]},
+			"expanded":{"acme":{"numFound":2,"start":0,"docs":[{"id":"2"},{"id":"3"}]}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"), solr.Collapse("customer"), solr.Expand(5, ""))
+		Expect(err).To(BeNil())
+		Expect(r.Response.Docs).To(HaveLen(2))
+		Expect(r.Expanded["acme"].NumFound).To(Equal(uint32(2)))
+		Expect(r.Expanded["acme"].Docs[1]["id"]).To(Equal("3"))
+		Expect(r.Expanded).NotTo(HaveKey("initech"))
+	})
+})
@@ -0,0 +1,54 @@
+package solr_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Gzip", func() {
+	var cli *fakeHTTPer
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK, body: `{"responseHeader":{"status":0,"rf":1,"min_rf":1}}`}
+	})
+
+	It("compresses update bodies over the threshold", func() {
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.GzipUpdates(gzip.BestSpeed, 20))
+		Expect(err).To(BeNil())
+		Expect(solrHttp.Update([]string{"http://a.foo.bar"}, false, []map[string]interface{}{{"id": "1"}})).To(BeNil())
+		Expect(solrHttp.Update([]string{"http://a.foo.bar"}, false, []map[string]interface{}{{"id": "1", "first_name": "shawn"}})).To(BeNil())
+
+		Expect(cli.requests[0].Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(cli.requests[1].Header.Get("Content-Encoding")).To(Equal("gzip"))
+		zr, err := gzip.NewReader(cli.requests[1].Body)
+		Expect(err).To(BeNil())
+		body, err := ioutil.ReadAll(zr)
+		Expect(err).To(BeNil())
+		Expect(string(body)).To(MatchJSON(`[{"id":"1","first_name":"shawn"}]`))
+	})
+
+	It("rejects an invalid compression level", func() {
+		_, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.GzipUpdates(42, 0))
+		Expect(err).To(Not(BeNil()))
+	})
+
+	It("decompresses gzip responses", func() {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(`{"response":{"numFound":1,"start":0,"docs":[{"id":"1"}]}}`))
+		zw.Close()
+		cli.body = buf.String()
+		cli.header = http.Header{"Content-Encoding": {"gzip"}}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.SelectContext(context.Background(), []string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		Expect(r.Response.Docs[0]["id"]).To(Equal("1"))
+	})
+})
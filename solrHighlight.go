@@ -0,0 +1,81 @@
+package solr
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HighlightMethod is the highlighter implementation solr uses
+type HighlightMethod string
+
+const (
+	HighlightUnified    HighlightMethod = "unified"
+	HighlightOriginal   HighlightMethod = "original"
+	HighlightFastVector HighlightMethod = "fastVector"
+)
+
+const highlightsDefaultKey = "_highlights_"
+
+// Highlighting is the highlighting section of a response, the snippets by field by doc id
+type Highlighting map[string]map[string][]string
+
+// Snippets returns the snippets of field for the doc id
+func (h Highlighting) Snippets(id string, field string) []string {
+	return h[id][field]
+}
+
+// Attach sets the snippets by field of every doc under key, "_highlights_" when key is empty.
+// Docs are matched by their string id, docs without snippets are left untouched
+func (h Highlighting) Attach(docs []map[string]interface{}, key string) {
+	if key == "" {
+		key = highlightsDefaultKey
+	}
+	for _, doc := range docs {
+		if snippets, ok := h[GetDocIdFromDoc(doc)]; ok {
+			doc[key] = snippets
+		}
+	}
+}
+
+// Highlight turns on highlighting of the fields, solr picks them from hl.fl defaults when none is given
+func Highlight(fields ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("hl", "true")
+		if len(fields) > 0 {
+			p.Set("hl.fl", strings.Join(fields, ","))
+		}
+	}
+}
+
+// HighlightWith sets the highlighter, unified by default since solr 9
+func HighlightWith(method HighlightMethod) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("hl.method", string(method))
+	}
+}
+
+// HighlightFragsize sets the approximate size in characters of a snippet, 0 highlights whole fields
+func HighlightFragsize(size int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("hl.fragsize", strconv.Itoa(size))
+	}
+}
+
+// HighlightSnippets sets the max number of snippets per field
+func HighlightSnippets(snippets int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("hl.snippets", strconv.Itoa(snippets))
+	}
+}
+
+// HighlightTags sets the markup around highlighted terms, <em> and </em> by default.
+// The original highlighter reads them from hl.simple.pre and hl.simple.post so both are set
+func HighlightTags(pre string, post string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("hl.tag.pre", pre)
+		p.Set("hl.tag.post", post)
+		p.Set("hl.simple.pre", pre)
+		p.Set("hl.simple.post", post)
+	}
+}
@@ -0,0 +1,50 @@
+package solr_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Highlighting", func() {
+	It("builds the highlighting params", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("title:solr"),
+			solr.Highlight("title", "body"), solr.HighlightWith(solr.HighlightUnified),
+			solr.HighlightFragsize(80), solr.HighlightSnippets(3), solr.HighlightTags("<b>", "</b>"))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("hl")).To(Equal("true"))
+		Expect(params.Get("hl.fl")).To(Equal("title,body"))
+		Expect(params.Get("hl.method")).To(Equal("unified"))
+		Expect(params.Get("hl.fragsize")).To(Equal("80"))
+		Expect(params.Get("hl.snippets")).To(Equal("3"))
+		Expect(params.Get("hl.tag.pre")).To(Equal("<b>"))
+		Expect(params.Get("hl.simple.post")).To(Equal("</b>"))
+	})
+
+	It("decodes the snippets and attaches them to the docs", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"response":{"numFound":2,"start":0,"docs":[{"id":"1"},{"id":"2"}]},
+			"highlighting":{"1":{"title":["<em>solr</em> in action"],"body":["a","b"]},"2":{}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("title:solr"), solr.Highlight("title", "body"))
+		Expect(err).To(BeNil())
+		Expect(r.Highlighting.Snippets("1", "title")).To(Equal([]string{"<em>solr</em> in action"}))
+		Expect(r.Highlighting.Snippets("1", "body")).To(HaveLen(2))
+		Expect(r.Highlighting.Snippets("3", "title")).To(BeNil())
+
+		r.Highlighting.Attach(r.Response.Docs, "")
+		Expect(r.Response.Docs[0]["_highlights_"]).To(Equal(map[string][]string{"title": {"<em>solr</em> in action"}, "body": {"a", "b"}}))
+		Expect(r.Response.Docs[1]["_highlights_"]).To(Equal(map[string][]string{}))
+	})
+})
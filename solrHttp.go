@@ -2,18 +2,25 @@ package solr
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	b64 "encoding/base64"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -33,9 +40,14 @@ type solrHttp struct {
 	readTimeoutSeconds    int
 	connectTimeoutSeconds int
 	router                Router
+	maxUpdateBytes        int
+	gzipUpdates           bool
+	gzipLevel             int
+	gzipMinBytes          int
+	wireFormat            WireFormat
 }
 
-func NewSolrHTTP(useHTTPS bool, collection string, options ...func(*solrHttp)) (SolrHTTP, error) {
+func NewSolrHTTP(useHTTPS bool, collection string, options ...func(*solrHttp)) (SolrHTTPContext, error) {
 	solrCli := solrHttp{collection: collection, minRf: 1, insecureSkipVerify: false, readTimeoutSeconds: 20, writeTimeoutSeconds: 30, connectTimeoutSeconds: 5}
 	logger := log.New(os.Stdout, "[SolrClient] ", log.LstdFlags)
 	solrCli.logger = &SolrLogger{logger}
@@ -44,6 +56,11 @@ func NewSolrHTTP(useHTTPS bool, collection string, options ...func(*solrHttp)) (
 	}
 
 	var err error
+	if solrCli.gzipUpdates {
+		if _, err = gzip.NewWriterLevel(ioutil.Discard, solrCli.gzipLevel); err != nil {
+			return nil, err
+		}
+	}
 	if solrCli.writeClient == nil {
 		solrCli.writeClient, err = getClient(solrCli.cert, useHTTPS, solrCli.insecureSkipVerify, solrCli.writeTimeoutSeconds, solrCli.connectTimeoutSeconds)
 		if err != nil {
@@ -66,8 +83,16 @@ func NewSolrHTTP(useHTTPS bool, collection string, options ...func(*solrHttp)) (
 }
 
 func (s *solrHttp) Update(nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) error {
+	_, err := s.UpdateContext(context.Background(), nodeUris, singleDoc, doc, opts...)
+	return err
+}
+
+// UpdateContext is Update with a context, cancelling the context aborts the request. The result carries
+// the replication factor achieved and, when the update asks for Versions, the version of every doc written
+func (s *solrHttp) UpdateContext(ctx context.Context, nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) (UpdateResult, error) {
+	var result UpdateResult
 	if len(nodeUris) == 0 {
-		return fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+		return result, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
 	}
 	nodeUri := nodeUris[0]
 	urlVals := url.Values{
@@ -79,24 +104,177 @@ func (s *solrHttp) Update(nodeUris []string, singleDoc bool, doc interface{}, op
 	}
 
 	uri := fmt.Sprintf("%s/%s/update", nodeUri, s.collection)
-	if singleDoc {
+	// javabin bodies go to the update handler itself, which takes single docs too
+	if singleDoc && s.wireFormat != WireJavabin {
 		uri += "/json/docs"
 	}
+	r, status, err := s.postBatch(ctx, nodeUri, uri, doc, urlVals)
+	// a split batch that failed part way still reports the docs of the parts that were written
+	result = UpdateResult{QTime: r.Response.QTime, RF: r.Response.RF, MinRF: r.Response.MinRF, Adds: r.Adds, Deletes: r.Deletes}
+	tooLarge, isTooLarge := err.(PayloadTooLargeError)
+	if err != nil && !isTooLarge {
+		return result, err
+	}
+
+	// the docs of a split batch that were indexed are checked against min_rf too
+	if r.Response.RF < r.Response.MinRF {
+		rfErr := NewSolrRFError(r.Response.RF, r.Response.MinRF).(SolrMinRFError)
+		rfErr.Status, rfErr.URL, rfErr.Node = status, uri, nodeUri
+		if isTooLarge {
+			tooLarge.MinRFError = &rfErr
+			return result, tooLarge
+		}
+		return result, rfErr
+	}
+	if isTooLarge {
+		return result, tooLarge
+	}
+	return result, nil
+}
+
+// Commit commits the pending updates of the whole collection, solr forwards the commit from the
+// node it is sent to to every shard. SoftCommit, WaitSearcher, OpenSearcher and ExpungeDeletes tune it
+func (s *solrHttp) Commit(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return s.updateCommand(ctx, nodeUris, "commit", opts...)
+}
+
+// Optimize merges the segments of the whole collection down to MaxSegments, one by default.
+// It is expensive, after large deletes a Commit with ExpungeDeletes is usually enough
+func (s *solrHttp) Optimize(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return s.updateCommand(ctx, nodeUris, "optimize", opts...)
+}
+
+// updateCommand sends a bodyless update carrying only the command param to a node picked by the router
+func (s *solrHttp) updateCommand(ctx context.Context, nodeUris []string, command string, opts ...func(url.Values)) (CommitResponse, error) {
+	var cr CommitResponse
+	if len(nodeUris) == 0 {
+		return cr, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+	}
+	nodeUri := s.router.GetUriFromList(nodeUris)
+	urlVals := url.Values{
+		command: {"true"},
+	}
+	for _, opt := range opts {
+		opt(urlVals)
+	}
+	uri := fmt.Sprintf("%s/%s/update", nodeUri, s.collection)
+	r, _, err := s.postUpdate(ctx, nodeUri, uri, nil, urlVals)
+	if err != nil {
+		return cr, err
+	}
+	cr.Status, cr.QTime = r.Response.Status, r.Response.QTime
+	return cr, nil
+}
+
+// postBatch is postUpdate splitting a batch solr rejects as too large in halves until the parts fit,
+// the docs still too large on their own are reported in a PayloadTooLargeError with the merged response of the rest.
+// A part failing for another reason stops the split, its error is returned with the merged response of the parts written
+func (s *solrHttp) postBatch(ctx context.Context, nodeUri string, uri string, doc interface{}, urlVals url.Values) (UpdateResponse, int, error) {
+	r, status, err := s.postUpdate(ctx, nodeUri, uri, doc, urlVals)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		return r, status, err
+	}
+	docs := reflect.ValueOf(doc)
+	if docs.Kind() != reflect.Slice || docs.Len() < 2 {
+		return r, status, err
+	}
+	s.logger.Debug(fmt.Sprintf("[SolrHTTP] batch of %d docs is too large, splitting it", docs.Len()))
+	var tooLarge PayloadTooLargeError
+	var written splitResponse
+	status, err = s.splitBatch(ctx, nodeUri, uri, docs, 0, urlVals, &tooLarge, &written)
+	if err == nil && len(tooLarge.Indexes) > 0 {
+		err = tooLarge
+	}
+	return written.r, status, err
+}
+
+// splitResponse merges the responses of the parts of a split batch that were written
+type splitResponse struct {
+	r      UpdateResponse
+	status int
+	sent   bool
+}
+
+func (w *splitResponse) add(r UpdateResponse, status int) {
+	if w.sent {
+		r = mergeUpdateResponses(w.r, r)
+	}
+	w.r, w.status, w.sent = r, status, true
+}
+
+// splitBatch posts the halves of docs, splitting them again while solr rejects them as too large. The parts
+// written are merged in written even when a later part fails, the status of the failure is returned with its error
+func (s *solrHttp) splitBatch(ctx context.Context, nodeUri string, uri string, docs reflect.Value, offset int, urlVals url.Values, tooLarge *PayloadTooLargeError, written *splitResponse) (int, error) {
+	half := docs.Len() / 2
+	for i, part := range []reflect.Value{docs.Slice(0, half), docs.Slice(half, docs.Len())} {
+		partOffset := offset + i*half
+		r, status, err := s.postUpdate(ctx, nodeUri, uri, part.Interface(), urlVals)
+		if errors.Is(err, ErrPayloadTooLarge) {
+			if part.Len() == 1 {
+				errors.As(err, &tooLarge.SolrError)
+				tooLarge.Indexes = append(tooLarge.Indexes, partOffset)
+				tooLarge.Docs = append(tooLarge.Docs, part.Index(0).Interface())
+				continue
+			}
+			if status, err := s.splitBatch(ctx, nodeUri, uri, part, partOffset, urlVals, tooLarge, written); err != nil {
+				return status, err
+			}
+			continue
+		}
+		if err != nil {
+			return status, err
+		}
+		written.add(r, status)
+	}
+	return written.status, nil
+}
+
+// postUpdate posts doc as json to the update uri and decodes the response, solr errors and
+// a non zero response status are returned as structured errors
+func (s *solrHttp) postUpdate(ctx context.Context, nodeUri string, uri string, doc interface{}, urlVals url.Values) (UpdateResponse, int, error) {
+	var r UpdateResponse
 	var buf bytes.Buffer
-	if doc != nil {
+	contentType := "application/json"
+	if doc != nil && s.wireFormat == WireJavabin {
+		contentType = javabinContentType
+		req, err := javabinUpdateRequest(doc)
+		if err != nil {
+			return r, 0, err
+		}
+		if err := newJavabinEncoder(&buf).Encode(req); err != nil {
+			return r, 0, err
+		}
+	} else if doc != nil {
 		enc := json.NewEncoder(&buf)
 		if err := enc.Encode(doc); err != nil {
-			return err
+			return r, 0, err
+		}
+	}
+	if s.maxUpdateBytes > 0 && buf.Len() > s.maxUpdateBytes {
+		solrErr := NewSolrError(http.StatusRequestEntityTooLarge, fmt.Sprintf("update of %d bytes is over the limit of %d bytes", buf.Len(), s.maxUpdateBytes)).(SolrError)
+		solrErr.URL, solrErr.Node = uri, nodeUri
+		return r, http.StatusRequestEntityTooLarge, solrErr
+	}
+	body := &buf
+	compressed := s.gzipUpdates && buf.Len() >= s.gzipMinBytes
+	if compressed {
+		var err error
+		if body, err = s.gzipBody(buf.Bytes()); err != nil {
+			return r, 0, err
 		}
 	}
 
-	req, err := http.NewRequest("POST", uri, &buf)
+	req, err := http.NewRequest("POST", uri, body)
 	if err != nil {
-		return err
+		return r, 0, err
 	}
+	req = req.WithContext(ctx)
 	req.URL.RawQuery = urlVals.Encode()
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
+	if compressed {
+		req.Header.Add("Content-Encoding", "gzip")
+	}
 	basicCred := s.getBasicCredential(s.user, s.password)
 	if basicCred != "" {
 		req.Header.Add("Authorization", fmt.Sprintf("Basic %s", basicCred))
@@ -104,53 +282,326 @@ func (s *solrHttp) Update(nodeUris []string, singleDoc bool, doc interface{}, op
 
 	start := time.Now()
 	resp, err := s.writeClient.Do(req)
-	if resp != nil {
-		s.router.AddSearchResult(time.Since(start), nodeUri, resp.StatusCode, err)
-	} else if resp == nil {
-		s.router.AddSearchResult(time.Since(start), nodeUri, http.StatusInternalServerError, err)
-	}
+	s.addSearchResult(ctx, start, nodeUri, resp, err)
 	if err != nil {
-		return err
+		return r, 0, contextError(ctx, err)
 	}
 	defer resp.Body.Close()
+	if err := gunzipBody(resp); err != nil {
+		return r, resp.StatusCode, NewSolrParseError(resp.StatusCode, err.Error())
+	}
 	if resp.StatusCode != 200 {
 		htmlData, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("error reading response body for StatusCode %d, err: %s", resp.StatusCode, err)
+			return r, resp.StatusCode, fmt.Errorf("error reading response body for StatusCode %d, err: %s", resp.StatusCode, err)
 		}
 		if resp.StatusCode == http.StatusNotFound {
-			return ErrNotFound
+			return r, resp.StatusCode, ErrNotFound
+		}
+		solrErr := ParseSolrError(resp.StatusCode, htmlData)
+		solrErr.URL, solrErr.Node = uri, nodeUri
+		if resp.StatusCode == http.StatusConflict {
+			return r, resp.StatusCode, NewVersionConflictError(solrErr)
 		}
 		if resp.StatusCode < 500 {
-			return NewSolrError(resp.StatusCode, string(htmlData))
+			return r, resp.StatusCode, solrErr
 		}
-		return NewSolrInternalError(resp.StatusCode, string(htmlData))
+		return r, resp.StatusCode, SolrInternalError{solrErr}
 	}
 
-	var r UpdateResponse
 	dec := json.NewDecoder(resp.Body)
 	if err := dec.Decode(&r); err != nil {
-		return NewSolrParseError(resp.StatusCode, err.Error())
+		if ctx.Err() != nil {
+			return r, resp.StatusCode, ctx.Err()
+		}
+		return r, resp.StatusCode, NewSolrParseError(resp.StatusCode, err.Error())
 	}
 
 	if r.Response.Status != 0 {
-		msg := r.Error.Msg
-		return NewSolrError(r.Response.Status, msg)
+		solrErr := NewSolrError(r.Response.Status, r.Error.Msg).(SolrError)
+		solrErr.Code, solrErr.Trace, solrErr.Metadata = r.Error.Code, r.Error.Trace, metadataMap(r.Error.Metadata)
+		solrErr.URL, solrErr.Node = uri, nodeUri
+		return r, resp.StatusCode, solrErr
 	}
+	return r, resp.StatusCode, nil
+}
 
-	if r.Response.RF < r.Response.MinRF {
-		return NewSolrRFError(r.Response.RF, r.Response.MinRF)
+func (s *solrHttp) Select(nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
+	return s.SelectContext(context.Background(), nodeUris, opts...)
+}
+
+// SelectContext is Select with a context, cancelling the context aborts the request
+func (s *solrHttp) SelectContext(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
+	var sr SolrResponse
+	if s.wireFormat == WireJavabin {
+		javabinOpts := make([]func(url.Values), 0, len(opts)+1)
+		javabinOpts = append(javabinOpts, opts...)
+		opts = append(javabinOpts, func(p url.Values) {
+			p["wt"] = []string{"javabin"}
+		})
 	}
-	return nil
+	resp, status, err := s.query(ctx, nodeUris, "select", opts...)
+	if err != nil {
+		sr.Status = status
+		return sr, err
+	}
+	defer resp.Body.Close()
+
+	if s.wireFormat == WireJavabin {
+		return sr, contextError(ctx, decodeJavabinResponse(resp.Body, &sr))
+	}
+	dec := json.NewDecoder(resp.Body)
+
+	return sr, contextError(ctx, dec.Decode(&sr))
 }
 
-func (s *solrHttp) Select(nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
+// SelectInto runs a select and decodes the matching docs into dst, a pointer to a slice of structs
+// whose fields are mapped with `solr:"field"` tags. The returned SolrResponse carries everything but the docs.
+// It always asks for json, Wire(WireJavabin) does not apply
+func (s *solrHttp) SelectInto(ctx context.Context, nodeUris []string, dst interface{}, opts ...func(url.Values)) (SolrResponse, error) {
+	var sr SolrResponse
+	resp, status, err := s.query(ctx, nodeUris, "select", opts...)
+	if err != nil {
+		sr.Status = status
+		return sr, err
+	}
+	defer resp.Body.Close()
+
+	slice, elemType, err := docsSlice(dst)
+	if err != nil {
+		return sr, err
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	err = decodeSelectStream(dec, &sr, func(dec *json.Decoder) error {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			return err
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := decodeDocInto(doc, elem, elemType); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+		return nil
+	})
+	if err != nil {
+		return sr, contextError(ctx, err)
+	}
+	reflect.ValueOf(dst).Elem().Set(slice)
+	return sr, nil
+}
+
+// SelectStream runs a select and hands the docs to fn one at a time as they are read off the wire,
+// the whole result set is never held in memory. fn gets the responseHeader and numFound with every doc,
+// the returned SolrResponse carries everything but the docs. An error returned by fn stops the stream and
+// is returned as is. It always asks for json, Wire(WireJavabin) does not apply
+func (s *solrHttp) SelectStream(ctx context.Context, nodeUris []string, fn func(header StreamHeader, doc map[string]interface{}) error, opts ...func(url.Values)) (SolrResponse, error) {
+	var sr SolrResponse
+	resp, status, err := s.query(ctx, nodeUris, "select", opts...)
+	if err != nil {
+		sr.Status = status
+		return sr, err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	var fnErr error
+	err = decodeSelectStream(dec, &sr, func(dec *json.Decoder) error {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			return err
+		}
+		header := StreamHeader{ResponseHeader: sr.ResponseHeader, NumFound: sr.Response.NumFound, Start: sr.Response.Start}
+		fnErr = fn(header, doc)
+		return fnErr
+	})
+	if fnErr != nil {
+		return sr, fnErr
+	}
+	return sr, contextError(ctx, err)
+}
+
+// RealTimeGet fetches docs by id from the /get handler, which sees updates before they are committed.
+// Unlike Select the request goes to the first of nodeUris, pass the leader first to read your own writes.
+// Ids that do not exist are simply missing from the response docs. The _version_ of the docs is decoded
+// as an exact int64 so it can be used for optimistic concurrency
+func (s *solrHttp) RealTimeGet(ctx context.Context, nodeUris []string, ids []string, opts ...func(url.Values)) (SolrResponse, error) {
+	var sr SolrResponse
 	if len(nodeUris) == 0 {
-		return SolrResponse{}, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+		return sr, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+	}
+	getOpts := make([]func(url.Values), 0, len(opts)+1)
+	getOpts = append(getOpts, opts...)
+	getOpts = append(getOpts, func(p url.Values) {
+		p["ids"] = []string{strings.Join(ids, ",")}
+	})
+	resp, status, err := s.queryNode(ctx, nodeUris[0], s.collection+"/get", getOpts...)
+	if err != nil {
+		sr.Status = status
+		return sr, err
 	}
+	defer resp.Body.Close()
 
-	nodeUri := s.router.GetUriFromList(nodeUris)
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&sr); err != nil {
+		return sr, contextError(ctx, err)
+	}
+	for _, doc := range sr.Response.Docs {
+		exactVersion(doc)
+	}
+	return sr, nil
+}
+
+// Suggest asks the /suggest handler of a node picked by the router for suggestions, pick the dictionaries
+// and the text with SuggestDictionary and SuggestQuery. The response is always json
+func (s *solrHttp) Suggest(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SuggestResponse, error) {
+	var sr SuggestResponse
+	suggestOpts := make([]func(url.Values), 0, len(opts)+1)
+	suggestOpts = append(suggestOpts, func(p url.Values) {
+		p["suggest"] = []string{"true"}
+	})
+	suggestOpts = append(suggestOpts, opts...)
+	resp, _, err := s.query(ctx, nodeUris, "suggest", suggestOpts...)
+	if err != nil {
+		return sr, err
+	}
+	defer resp.Body.Close()
+
+	return sr, contextError(ctx, json.NewDecoder(resp.Body).Decode(&sr))
+}
+
+// Terms lists the indexed terms of the terms.fl fields from the /terms handler of a node picked by the router.
+// The handler does not distribute by default, use TermsCollection to list the terms of every shard
+func (s *solrHttp) Terms(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (TermsResponse, error) {
+	var tr TermsResponse
+	termsOpts := make([]func(url.Values), 0, len(opts)+1)
+	termsOpts = append(termsOpts, func(p url.Values) {
+		p["terms"] = []string{"true"}
+	})
+	termsOpts = append(termsOpts, opts...)
+	resp, _, err := s.query(ctx, nodeUris, "terms", termsOpts...)
+	if err != nil {
+		return tr, err
+	}
+	defer resp.Body.Close()
+
+	return tr, contextError(ctx, json.NewDecoder(resp.Body).Decode(&tr))
+}
+
+// MoreLikeThis asks the /mlt handler of a node picked by the router for the docs similar to the doc matching
+// the q param, or to text when it is not empty. The text is posted as the content stream of the request since
+// recent solr versions disable stream.body. The mlt handler does not distribute, only the docs of the core
+// that answers are compared
+func (s *solrHttp) MoreLikeThis(ctx context.Context, nodeUris []string, text string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	var mr MoreLikeThisResponse
+	if len(nodeUris) == 0 {
+		return mr, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+	}
+	var resp *http.Response
 	var err error
+	if text == "" {
+		resp, _, err = s.query(ctx, nodeUris, "mlt", opts...)
+	} else {
+		urlValues := url.Values{
+			"wt": {"json"},
+		}
+		for _, opt := range opts {
+			opt(urlValues)
+		}
+		nodeUri := s.router.GetUriFromList(nodeUris)
+		u := fmt.Sprintf("%s/%s/mlt?%s", nodeUri, s.collection, urlValues.Encode())
+		resp, _, err = s.postQuery(ctx, nodeUri, u, "text/plain; charset=utf-8", strings.NewReader(text))
+	}
+	if err != nil {
+		return mr, err
+	}
+	defer resp.Body.Close()
+
+	return mr, contextError(ctx, json.NewDecoder(resp.Body).Decode(&mr))
+}
+
+// MoreLikeThisCores is MoreLikeThis against the /mlt handler of a core. coreUris are the core urls of the
+// replicas of one shard, as returned by SolrShardLocator.GetShardCores, so the docs of that shard are compared
+func (s *solrHttp) MoreLikeThisCores(ctx context.Context, coreUris []string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	var mr MoreLikeThisResponse
+	resp, _, err := s.queryPath(ctx, coreUris, "mlt", opts...)
+	if err != nil {
+		return mr, err
+	}
+	defer resp.Body.Close()
+
+	return mr, contextError(ctx, json.NewDecoder(resp.Body).Decode(&mr))
+}
+
+// Export streams the docValues of every doc of one shard from the /export handler to fn. coreUris are
+// the core urls of the replicas of the shard, as returned by SolrShardLocator.GetShardCores, since the export
+// handler does not distribute. Fields and Sort are required, an exception marker in the stream is
+// returned as a SolrExportError
+func (s *solrHttp) Export(ctx context.Context, coreUris []string, fn func(doc map[string]interface{}) error, opts ...func(url.Values)) (ExportResponse, error) {
+	var er ExportResponse
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	if params.Get("fl") == "" || params.Get("sort") == "" {
+		return er, fmt.Errorf("[SolrHTTP] export: fl and sort are required")
+	}
+	resp, _, err := s.queryPath(ctx, coreUris, "export", opts...)
+	if err != nil {
+		return er, err
+	}
+	defer resp.Body.Close()
+
+	var sr SolrResponse
+	var fnErr error
+	dec := json.NewDecoder(resp.Body)
+	err = decodeSelectStream(dec, &sr, func(dec *json.Decoder) error {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			return err
+		}
+		if exception, ok := doc["EXCEPTION"]; ok {
+			er.EOF, _ = doc["EOF"].(bool)
+			return NewSolrExportError(fmt.Sprint(exception))
+		}
+		if eof, _ := doc["EOF"].(bool); eof {
+			er.EOF = true
+			return nil
+		}
+		er.Exported++
+		fnErr = fn(doc)
+		return fnErr
+	})
+	er.NumFound = sr.Response.NumFound
+	if fnErr != nil {
+		return er, fnErr
+	}
+	if err != nil {
+		return er, contextError(ctx, err)
+	}
+	// older export writers end the stream without an EOF marker
+	er.EOF = true
+	return er, nil
+}
+
+// query posts the params to the handler of a node picked by the router and returns the response
+// when solr answers with a success status, the caller must close the response body
+func (s *solrHttp) query(ctx context.Context, nodeUris []string, handler string, opts ...func(url.Values)) (*http.Response, int, error) {
+	return s.queryPath(ctx, nodeUris, s.collection+"/"+handler, opts...)
+}
+
+// queryPath is query against a path relative to the node uri
+func (s *solrHttp) queryPath(ctx context.Context, nodeUris []string, path string, opts ...func(url.Values)) (*http.Response, int, error) {
+	if len(nodeUris) == 0 {
+		return nil, 0, fmt.Errorf("[SolrHTTP] nodeuris: empty node uris is not valid")
+	}
+	return s.queryNode(ctx, s.router.GetUriFromList(nodeUris), path, opts...)
+}
+
+// queryNode is queryPath against the given node, bypassing the router
+func (s *solrHttp) queryNode(ctx context.Context, nodeUri string, path string, opts ...func(url.Values)) (*http.Response, int, error) {
 	urlValues := url.Values{
 		"wt": {"json"},
 	}
@@ -158,46 +609,116 @@ func (s *solrHttp) Select(nodeUris []string, opts ...func(url.Values)) (SolrResp
 		opt(urlValues)
 	}
 
-	var sr SolrResponse
-	u := fmt.Sprintf("%s/%s/select", nodeUri, s.collection)
-	body := bytes.NewBufferString(urlValues.Encode())
+	u := fmt.Sprintf("%s/%s", nodeUri, path)
+	return s.postQuery(ctx, nodeUri, u, "application/x-www-form-urlencoded", bytes.NewBufferString(urlValues.Encode()))
+}
+
+// postQuery posts body to the url u of a node and checks the response status like queryNode
+func (s *solrHttp) postQuery(ctx context.Context, nodeUri string, u string, contentType string, body io.Reader) (*http.Response, int, error) {
 	req, err := http.NewRequest("POST", u, body)
 	if err != nil {
-		return sr, err
+		return nil, 0, err
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-Type", contentType)
 	basicCred := s.getBasicCredential(s.user, s.password)
 	if basicCred != "" {
 		req.Header.Add("Authorization", fmt.Sprintf("Basic %s", basicCred))
 	}
 	start := time.Now()
 	resp, err := s.queryClient.Do(req)
-	if resp != nil {
-		s.router.AddSearchResult(time.Since(start), nodeUri, resp.StatusCode, err)
-	} else if resp == nil {
-		s.router.AddSearchResult(time.Since(start), nodeUri, http.StatusInternalServerError, err)
-	}
+	s.addSearchResult(ctx, start, nodeUri, resp, err)
 	if err != nil {
-		return sr, err
+		return nil, 0, contextError(ctx, err)
+	}
+	if err := gunzipBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, resp.StatusCode, NewSolrParseError(resp.StatusCode, err.Error())
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		sr.Status = 404
-		return sr, ErrNotFound
+		resp.Body.Close()
+		return nil, http.StatusNotFound, ErrNotFound
 	}
 	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
 		htmlData, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return sr, err
+			return nil, resp.StatusCode, contextError(ctx, err)
 		}
-		sr.Status = resp.StatusCode
-		return sr, NewSolrError(resp.StatusCode, string(htmlData))
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), javabinResponseMimeType) {
+			if jsonData, err := javabinToJSON(htmlData); err == nil {
+				htmlData = jsonData
+			}
+		}
+		solrErr := ParseSolrError(resp.StatusCode, htmlData)
+		solrErr.URL, solrErr.Node = u, nodeUri
+		return nil, resp.StatusCode, solrErr
 	}
+	return resp, resp.StatusCode, nil
+}
 
-	dec := json.NewDecoder(resp.Body)
+// gzipBody compresses an update body at the configured level
+func (s *solrHttp) gzipBody(b []byte) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, s.gzipLevel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
 
-	return sr, dec.Decode(&sr)
+// gunzipBody swaps a gzip encoded response body for its decompressed stream. The default transport
+// already decompresses the responses it asked gzip for, this covers the HTTPers that do not
+func gunzipBody(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body = gzipReadCloser{zr, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	return nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (r gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
+
+// addSearchResult records the request against the router, requests cancelled by
+// the caller say nothing about the node so they are not recorded
+func (s *solrHttp) addSearchResult(ctx context.Context, start time.Time, nodeUri string, resp *http.Response, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if resp != nil {
+		s.router.AddSearchResult(time.Since(start), nodeUri, resp.StatusCode, err)
+	} else {
+		s.router.AddSearchResult(time.Since(start), nodeUri, http.StatusInternalServerError, err)
+	}
+}
+
+// contextError returns the context error in place of err once the context is done
+// so callers can tell a cancelled or expired request apart from a solr error
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
 }
 
 func getMapChunks(in []map[string]interface{}, chunkSize int) [][]map[string]interface{} {
@@ -228,9 +749,21 @@ func getidChunks(in []string, chunkSize int) [][]string {
 	return out
 }
 
+// DeleteStreamBody deletes by query through the stream.body param, which recent solr versions disable.
+//
+// Deprecated: use DeleteByQuery
 func DeleteStreamBody(filter string) func(url.Values) {
 	return func(p url.Values) {
-		p["stream.body"] = []string{fmt.Sprintf("<delete><query>%s</query></delete>", filter)}
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(filter))
+		p["stream.body"] = []string{fmt.Sprintf("<delete><query>%s</query></delete>", escaped.String())}
+	}
+}
+
+// Fields sets the field list returned for each doc
+func Fields(fields ...string) func(url.Values) {
+	return func(p url.Values) {
+		p["fl"] = []string{strings.Join(fields, ",")}
 	}
 }
 
@@ -247,9 +780,11 @@ func ClusterStateVersion(version int, collection string) func(url.Values) {
 }
 
 //Helper funcs for setting the solr query params
+
+// FilterQuery adds a filter query, the docs must match every one of them
 func FilterQuery(fq string) func(url.Values) {
 	return func(p url.Values) {
-		p["fq"] = []string{fq}
+		p.Add("fq", fq)
 	}
 }
 
@@ -297,6 +832,55 @@ func Commit(commit bool) func(url.Values) {
 	}
 }
 
+// SoftCommit makes a commit open a new searcher without flushing the index to stable storage
+func SoftCommit(softCommit bool) func(url.Values) {
+	return func(p url.Values) {
+		p["softCommit"] = []string{strconv.FormatBool(softCommit)}
+	}
+}
+
+// CommitWithin asks solr to commit the update within d instead of committing it right away
+func CommitWithin(d time.Duration) func(url.Values) {
+	return func(p url.Values) {
+		p["commitWithin"] = []string{strconv.FormatInt(int64(d/time.Millisecond), 10)}
+	}
+}
+
+// WaitSearcher sets whether a commit waits for the new searcher to be registered before returning
+func WaitSearcher(waitSearcher bool) func(url.Values) {
+	return func(p url.Values) {
+		p["waitSearcher"] = []string{strconv.FormatBool(waitSearcher)}
+	}
+}
+
+// OpenSearcher sets whether a hard commit opens a new searcher, false only makes the updates durable
+func OpenSearcher(openSearcher bool) func(url.Values) {
+	return func(p url.Values) {
+		p["openSearcher"] = []string{strconv.FormatBool(openSearcher)}
+	}
+}
+
+// ExpungeDeletes makes a commit merge away the segments holding deleted docs
+func ExpungeDeletes(expungeDeletes bool) func(url.Values) {
+	return func(p url.Values) {
+		p["expungeDeletes"] = []string{strconv.FormatBool(expungeDeletes)}
+	}
+}
+
+// MaxSegments sets the number of segments an optimize merges down to
+func MaxSegments(maxSegments int) func(url.Values) {
+	return func(p url.Values) {
+		p["maxSegments"] = []string{strconv.Itoa(maxSegments)}
+	}
+}
+
+// Versions asks solr to return the version assigned to every doc added or deleted by id in the UpdateResult
+func Versions(versions bool) func(url.Values) {
+	return func(p url.Values) {
+		p["versions"] = []string{strconv.FormatBool(versions)}
+	}
+}
+
 func Cursor(c string) func(url.Values) {
 	return func(p url.Values) {
 		p["cursorMark"] = []string{c}
@@ -424,6 +1008,31 @@ func ConnectionTimeout(seconds int) func(*solrHttp) {
 	}
 }
 
+// Wire sets the format of the select responses and update bodies, WireJSON by default
+func Wire(format WireFormat) func(*solrHttp) {
+	return func(c *solrHttp) {
+		c.wireFormat = format
+	}
+}
+
+// GzipUpdates compresses the update bodies of at least minBytes at the given compress/gzip level,
+// solr must be set up to inflate gzip requests
+func GzipUpdates(level int, minBytes int) func(*solrHttp) {
+	return func(c *solrHttp) {
+		c.gzipUpdates = true
+		c.gzipLevel = level
+		c.gzipMinBytes = minBytes
+	}
+}
+
+// MaxUpdateBytes sets the size of the largest update body sent to solr, larger batches are split
+// before being sent as if solr had rejected them as too large. Zero, the default, sends any size
+func MaxUpdateBytes(bytes int) func(*solrHttp) {
+	return func(c *solrHttp) {
+		c.maxUpdateBytes = bytes
+	}
+}
+
 func InsecureSkipVerify(insecureSkipVerify bool) func(*solrHttp) {
 	return func(c *solrHttp) {
 		c.insecureSkipVerify = insecureSkipVerify
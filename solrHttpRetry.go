@@ -1,6 +1,7 @@
 package solr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -8,56 +9,235 @@ import (
 )
 
 type SolrHttpRetrier struct {
-	solrCli            SolrHTTP
+	solrCli            SolrHTTPContext
 	retries            int
 	exponentialBackoff time.Duration
 	readTimeout        time.Duration
 	updateTimeout      time.Duration
 }
 
-func NewSolrHttpRetrier(solrHttp SolrHTTP, retries int, exponentialBackoff time.Duration) SolrHTTP {
-	solrRetrier := SolrHttpRetrier{solrCli: solrHttp, retries: retries, exponentialBackoff: exponentialBackoff}
+// NewSolrHttpRetrier retries the calls of solrHttp. A solrHttp that does not implement SolrHTTPContext
+// still has its Select and Update retried, without cancelling the request itself, and errors on the
+// other handlers
+func NewSolrHttpRetrier(solrHttp SolrHTTP, retries int, exponentialBackoff time.Duration) SolrHTTPContext {
+	solrCli, ok := solrHttp.(SolrHTTPContext)
+	if !ok {
+		solrCli = noContextHTTP{solrHttp}
+	}
+	solrRetrier := SolrHttpRetrier{solrCli: solrCli, retries: retries, exponentialBackoff: exponentialBackoff}
 	return &solrRetrier
 }
 
 func (s *SolrHttpRetrier) Select(nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
+	return s.SelectContext(context.Background(), nodeUris, opts...)
+}
+
+// SelectContext retries like Select, it stops retrying and returns the context error once ctx is done
+func (s *SolrHttpRetrier) SelectContext(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
 	if len(nodeUris) == 0 {
 		return SolrResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
 	}
-	now := time.Now()
 	var resp SolrResponse
-	var err error
-	backoff := s.exponentialBackoff
-	for attempt := 0; attempt < s.retries; attempt++ {
-		resp, err = s.solrCli.Select(nodeUris, opts...)
-		if err == ErrNotFound {
-			return resp, err
-		}
-		if err != nil {
-			s.Logger().Debug(fmt.Sprintf("[Solr Http Retrier] Error Retrying %v ", err))
-			backoff = s.backoff(backoff)
-			s.Logger().Debug(fmt.Sprintf("Sleeping attempt: %d, for time: %v running for: %v ", attempt, backoff, time.Since(now)))
-			continue
-		}
-		if attempt > 0 {
-			s.Logger().Debug(fmt.Sprintf("[Solr Http Retrier] healed after %d", attempt))
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.SelectContext(ctx, nodeUris, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (s *SolrHttpRetrier) SelectInto(ctx context.Context, nodeUris []string, dst interface{}, opts ...func(url.Values)) (SolrResponse, error) {
+	if len(nodeUris) == 0 {
+		return SolrResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp SolrResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.SelectInto(ctx, nodeUris, dst, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// SelectStream only retries until the first doc reached fn, a stream failing after that is
+// returned as is since a retry would hand the same docs to fn twice
+func (s *SolrHttpRetrier) SelectStream(ctx context.Context, nodeUris []string, fn func(header StreamHeader, doc map[string]interface{}) error, opts ...func(url.Values)) (SolrResponse, error) {
+	if len(nodeUris) == 0 {
+		return SolrResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp SolrResponse
+	var streaming bool
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.SelectStream(ctx, nodeUris, func(header StreamHeader, doc map[string]interface{}) error {
+			streaming = true
+			return fn(header, doc)
+		}, opts...)
+		if err != nil && streaming {
+			return stopRetry{err}
 		}
-		break
+		return err
+	})
+	return resp, err
+}
+
+// RealTimeGet moves on to the next node on each attempt, like Update
+func (s *SolrHttpRetrier) RealTimeGet(ctx context.Context, nodeUris []string, ids []string, opts ...func(url.Values)) (SolrResponse, error) {
+	if len(nodeUris) == 0 {
+		return SolrResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp SolrResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		uri := nodeUris[attempt%len(nodeUris)]
+		resp, err = s.solrCli.RealTimeGet(ctx, []string{uri}, ids, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// Suggest retries like Select
+func (s *SolrHttpRetrier) Suggest(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SuggestResponse, error) {
+	if len(nodeUris) == 0 {
+		return SuggestResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp SuggestResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.Suggest(ctx, nodeUris, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// Terms retries like Select
+func (s *SolrHttpRetrier) Terms(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (TermsResponse, error) {
+	if len(nodeUris) == 0 {
+		return TermsResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
 	}
+	var resp TermsResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.Terms(ctx, nodeUris, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// MoreLikeThis retries like Select
+func (s *SolrHttpRetrier) MoreLikeThis(ctx context.Context, nodeUris []string, text string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	if len(nodeUris) == 0 {
+		return MoreLikeThisResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp MoreLikeThisResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.MoreLikeThis(ctx, nodeUris, text, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// MoreLikeThisCores retries like Select
+func (s *SolrHttpRetrier) MoreLikeThisCores(ctx context.Context, coreUris []string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	if len(coreUris) == 0 {
+		return MoreLikeThisResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp MoreLikeThisResponse
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.MoreLikeThisCores(ctx, coreUris, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// Export retries like SelectStream, only until the first doc reached fn
+func (s *SolrHttpRetrier) Export(ctx context.Context, coreUris []string, fn func(doc map[string]interface{}) error, opts ...func(url.Values)) (ExportResponse, error) {
+	if len(coreUris) == 0 {
+		return ExportResponse{}, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	var resp ExportResponse
+	var streaming bool
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = s.solrCli.Export(ctx, coreUris, func(doc map[string]interface{}) error {
+			streaming = true
+			return fn(doc)
+		}, opts...)
+		if err != nil && streaming {
+			return stopRetry{err}
+		}
+		return err
+	})
 	return resp, err
 }
 
 func (s *SolrHttpRetrier) Update(nodeUris []string, jsonDocs bool, doc interface{}, opts ...func(url.Values)) error {
+	_, err := s.UpdateContext(context.Background(), nodeUris, jsonDocs, doc, opts...)
+	return err
+}
+
+// UpdateContext retries like Update, it stops retrying and returns the context error once ctx is done
+func (s *SolrHttpRetrier) UpdateContext(ctx context.Context, nodeUris []string, jsonDocs bool, doc interface{}, opts ...func(url.Values)) (UpdateResult, error) {
+	var result UpdateResult
 	if len(nodeUris) == 0 {
-		return errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+		return result, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
 	}
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		uri := nodeUris[attempt%len(nodeUris)]
+		result, err = s.solrCli.UpdateContext(ctx, []string{uri}, jsonDocs, doc, opts...)
+		return err
+	})
+	return result, err
+}
+
+// Commit retries the commit on the next node uri
+func (s *SolrHttpRetrier) Commit(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return s.updateCommand(ctx, nodeUris, s.solrCli.Commit, opts...)
+}
+
+// Optimize retries the optimize on the next node uri
+func (s *SolrHttpRetrier) Optimize(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return s.updateCommand(ctx, nodeUris, s.solrCli.Optimize, opts...)
+}
+
+func (s *SolrHttpRetrier) updateCommand(ctx context.Context, nodeUris []string, command func(context.Context, []string, ...func(url.Values)) (CommitResponse, error), opts ...func(url.Values)) (CommitResponse, error) {
+	var resp CommitResponse
+	if len(nodeUris) == 0 {
+		return resp, errors.New("[Solr HTTP Retrier]Length of nodes in solr is empty")
+	}
+	err := s.retry(ctx, func(attempt int) error {
+		var err error
+		resp, err = command(ctx, []string{nodeUris[attempt%len(nodeUris)]}, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (s *SolrHttpRetrier) Logger() Logger {
+	return s.solrCli.Logger()
+}
+
+// retry calls fn with the attempt number until it succeeds, returns ErrNotFound, a version conflict or a payload too large,
+// the context is done or the retries run out and returns the last error
+func (s *SolrHttpRetrier) retry(ctx context.Context, fn func(attempt int) error) error {
 	now := time.Now()
 	var err error
 	backoff := s.exponentialBackoff
 	for attempt := 0; attempt < s.retries; attempt++ {
-		uri := nodeUris[attempt%len(nodeUris)]
-		err = s.solrCli.Update([]string{uri}, jsonDocs, doc, opts...)
-		if err == ErrNotFound {
+		err = fn(attempt)
+		if stop, ok := err.(stopRetry); ok {
+			return stop.error
+		}
+		if err == ErrNotFound || isContextError(err) {
+			return err
+		}
+		if _, ok := err.(VersionConflictError); ok {
+			return err
+		}
+		if errors.Is(err, ErrPayloadTooLarge) {
 			return err
 		}
 		if err != nil {
@@ -66,7 +246,10 @@ func (s *SolrHttpRetrier) Update(nodeUris []string, jsonDocs bool, doc interface
 			} else {
 				s.Logger().Debug(fmt.Sprintf("[Solr Http Retrier] Error Retrying %v ", err))
 			}
-			backoff = s.backoff(backoff)
+			backoff, err = s.backoff(ctx, backoff, err)
+			if isContextError(err) {
+				return err
+			}
 			s.Logger().Debug(fmt.Sprintf("Sleeping attempt: %d, for time: %v running for: %v ", attempt, backoff, time.Since(now)))
 			continue
 		}
@@ -78,13 +261,82 @@ func (s *SolrHttpRetrier) Update(nodeUris []string, jsonDocs bool, doc interface
 	return err
 }
 
-func (s *SolrHttpRetrier) Logger() Logger {
-	return s.solrCli.Logger()
+// backoff sleeps for double the backoffInterval and returns the new backoffInterval,
+// the sleep is cut short when ctx is done and the context error is returned in place of err
+func (s *SolrHttpRetrier) backoff(ctx context.Context, backoffInterval time.Duration, err error) (time.Duration, error) {
+	backoffInterval = backoffInterval * time.Duration(2)
+	timer := time.NewTimer(backoffInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return backoffInterval, ctx.Err()
+	case <-timer.C:
+		return backoffInterval, err
+	}
 }
 
-//exponential backoff of the backoffInterval and returns new backoffInterval
-func (s *SolrHttpRetrier) backoff(backoffInterval time.Duration) time.Duration {
-	backoffInterval = backoffInterval * time.Duration(2)
-	time.Sleep(backoffInterval)
-	return backoffInterval
+// stopRetry wraps an error that must be returned without retrying
+type stopRetry struct {
+	error
+}
+
+func isContextError(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
+
+// errNoContext is returned by the handlers a SolrHTTP without context support cannot serve
+var errNoContext = errors.New("[Solr HTTP Retrier] the solr client does not implement SolrHTTPContext")
+
+// noContextHTTP serves the SolrHTTPContext methods of a client that only implements SolrHTTP,
+// selects and updates ignore the context and the other handlers return errNoContext
+type noContextHTTP struct {
+	SolrHTTP
+}
+
+func (n noContextHTTP) SelectContext(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SolrResponse, error) {
+	return n.Select(nodeUris, opts...)
+}
+
+func (n noContextHTTP) SelectInto(ctx context.Context, nodeUris []string, dst interface{}, opts ...func(url.Values)) (SolrResponse, error) {
+	return SolrResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) SelectStream(ctx context.Context, nodeUris []string, fn func(header StreamHeader, doc map[string]interface{}) error, opts ...func(url.Values)) (SolrResponse, error) {
+	return SolrResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) RealTimeGet(ctx context.Context, nodeUris []string, ids []string, opts ...func(url.Values)) (SolrResponse, error) {
+	return SolrResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) Export(ctx context.Context, coreUris []string, fn func(doc map[string]interface{}) error, opts ...func(url.Values)) (ExportResponse, error) {
+	return ExportResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) Suggest(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (SuggestResponse, error) {
+	return SuggestResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) MoreLikeThis(ctx context.Context, nodeUris []string, text string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	return MoreLikeThisResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) MoreLikeThisCores(ctx context.Context, coreUris []string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	return MoreLikeThisResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) Terms(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (TermsResponse, error) {
+	return TermsResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) UpdateContext(ctx context.Context, nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) (UpdateResult, error) {
+	return UpdateResult{}, n.Update(nodeUris, singleDoc, doc, opts...)
+}
+
+func (n noContextHTTP) Commit(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return CommitResponse{}, stopRetry{errNoContext}
+}
+
+func (n noContextHTTP) Optimize(ctx context.Context, nodeUris []string, opts ...func(url.Values)) (CommitResponse, error) {
+	return CommitResponse{}, stopRetry{errNoContext}
 }
@@ -0,0 +1,125 @@
+package solr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+// fakeHTTPer answers every request with status, header and body, or with the next of statuses and bodies when set
+type fakeHTTPer struct {
+	status   int
+	header   http.Header
+	body     string
+	statuses []int
+	bodies   []string
+	requests []*http.Request
+	lock     sync.Mutex
+}
+
+func (f *fakeHTTPer) Do(req *http.Request) (*http.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.requests = append(f.requests, req)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	status, body := f.status, f.body
+	if len(f.statuses) > 0 {
+		status = f.statuses[0]
+		f.statuses = f.statuses[1:]
+	}
+	if len(f.bodies) > 0 {
+		body = f.bodies[0]
+		f.bodies = f.bodies[1:]
+	}
+	header := http.Header{}
+	for k, v := range f.header {
+		header[k] = v
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		Request:    req,
+	}, nil
+}
+
+var _ = Describe("Solr Http Retrier", func() {
+	var cli *fakeHTTPer
+	var retrier solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusServiceUnavailable, body: "unavailable"}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		retrier = solr.NewSolrHttpRetrier(solrHttp, 10, 50*time.Millisecond)
+	})
+
+	It("stops retrying selects when the deadline expires", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+		defer cancel()
+		_, err := retrier.SelectContext(ctx, []string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(len(cli.requests)).To(BeNumerically("<", 10))
+	})
+
+	It("stops retrying updates when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err := retrier.UpdateContext(ctx, []string{"http://a.foo.bar"}, true, map[string]interface{}{"id": "1"})
+		Expect(err).To(Equal(context.Canceled))
+		Expect(len(cli.requests)).To(BeNumerically("<", 10))
+	})
+
+	It("does not send requests for a done context", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := retrier.SelectContext(ctx, []string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(Equal(context.Canceled))
+		Expect(cli.requests[0].Context().Err()).To(Equal(context.Canceled))
+	})
+
+	It("retries the select of a client without context support", func() {
+		plain := &plainHTTP{errs: []error{errors.New("unavailable"), nil}}
+		retrier := solr.NewSolrHttpRetrier(plain, 3, time.Millisecond)
+		_, err := retrier.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		Expect(plain.selects).To(Equal(2))
+
+		_, err = retrier.Suggest(context.Background(), []string{"http://a.foo.bar"})
+		Expect(err).NotTo(BeNil())
+	})
+})
+
+// plainHTTP only implements SolrHTTP, like a client written before SolrHTTPContext
+type plainHTTP struct {
+	errs    []error
+	selects int
+}
+
+func (p *plainHTTP) Select(nodeUris []string, opts ...func(url.Values)) (solr.SolrResponse, error) {
+	p.selects++
+	var err error
+	if len(p.errs) > 0 {
+		err, p.errs = p.errs[0], p.errs[1:]
+	}
+	return solr.SolrResponse{}, err
+}
+
+func (p *plainHTTP) Update(nodeUris []string, singleDoc bool, doc interface{}, opts ...func(url.Values)) error {
+	return nil
+}
+
+func (p *plainHTTP) Logger() solr.Logger {
+	return &solr.SolrLogger{Logger: log.New(ioutil.Discard, "", 0)}
+}
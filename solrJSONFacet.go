@@ -0,0 +1,328 @@
+package solr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// JSONFacet builds a facet of the json facet api. The facet returned by NewJSONFacets is the
+// top level of the request, it only holds the named facets and stats added to it
+type JSONFacet struct {
+	root   bool
+	params map[string]interface{}
+	facets map[string]interface{}
+}
+
+// NewJSONFacets starts a json.facet request, pass it to JSONFacets
+func NewJSONFacets() *JSONFacet {
+	return &JSONFacet{root: true, params: make(map[string]interface{}), facets: make(map[string]interface{})}
+}
+
+func newJSONFacet(facetType string) *JSONFacet {
+	return &JSONFacet{params: map[string]interface{}{"type": facetType}, facets: make(map[string]interface{})}
+}
+
+// NewTermsFacet buckets the docs by the terms of field
+func NewTermsFacet(field string) *JSONFacet {
+	return newJSONFacet("terms").Param("field", field)
+}
+
+// NewRangeFacet buckets the docs by ranges of field from start to end in steps of gap,
+// dates take date strings and date math like +1DAY
+func NewRangeFacet(field string, start interface{}, end interface{}, gap interface{}) *JSONFacet {
+	return newJSONFacet("range").Param("field", field).Param("start", start).Param("end", end).Param("gap", gap)
+}
+
+// NewQueryFacet counts the docs matching q, its sub facets are computed over them
+func NewQueryFacet(q string) *JSONFacet {
+	return newJSONFacet("query").Param("q", q)
+}
+
+// NewHeatmapFacet counts the docs of a spatial field in a grid over geom, a WKT or [x1 y1 TO x2 y2] rectangle
+func NewHeatmapFacet(field string, geom string) *JSONFacet {
+	return newJSONFacet("heatmap").Param("field", field).Param("geom", geom)
+}
+
+// Param sets any param of the facet
+func (f *JSONFacet) Param(name string, value interface{}) *JSONFacet {
+	f.params[name] = value
+	return f
+}
+
+// Limit sets the number of buckets returned, -1 returns them all
+func (f *JSONFacet) Limit(limit int) *JSONFacet {
+	return f.Param("limit", limit)
+}
+
+// Offset skips the first buckets
+func (f *JSONFacet) Offset(offset int) *JSONFacet {
+	return f.Param("offset", offset)
+}
+
+// Sort orders the buckets, like "count desc" or "avg_price asc" to sort on a stat
+func (f *JSONFacet) Sort(sort string) *JSONFacet {
+	return f.Param("sort", sort)
+}
+
+// MinCount drops the buckets counting less docs than minCount
+func (f *JSONFacet) MinCount(minCount int) *JSONFacet {
+	return f.Param("mincount", minCount)
+}
+
+// Prefix limits a terms facet to the terms starting with prefix
+func (f *JSONFacet) Prefix(prefix string) *JSONFacet {
+	return f.Param("prefix", prefix)
+}
+
+// Missing adds a bucket for the docs without a value
+func (f *JSONFacet) Missing(missing bool) *JSONFacet {
+	return f.Param("missing", missing)
+}
+
+// NumBuckets returns the number of buckets before limit and offset
+func (f *JSONFacet) NumBuckets(numBuckets bool) *JSONFacet {
+	return f.Param("numBuckets", numBuckets)
+}
+
+// AllBuckets adds a bucket spanning every bucket
+func (f *JSONFacet) AllBuckets(allBuckets bool) *JSONFacet {
+	return f.Param("allBuckets", allBuckets)
+}
+
+// Other adds the before, after and between buckets of a range facet, "all" for every one
+func (f *JSONFacet) Other(other ...string) *JSONFacet {
+	return f.Param("other", other)
+}
+
+// HardEnd ends the last range bucket at end instead of start plus a whole gap
+func (f *JSONFacet) HardEnd(hardEnd bool) *JSONFacet {
+	return f.Param("hardend", hardEnd)
+}
+
+// ExcludeTags computes the facet without the filters tagged with tags
+func (f *JSONFacet) ExcludeTags(tags ...string) *JSONFacet {
+	return f.domain("excludeTags", tags)
+}
+
+// DomainFilter narrows the docs the facet is computed over
+func (f *JSONFacet) DomainFilter(filters ...string) *JSONFacet {
+	return f.domain("filter", filters)
+}
+
+// DomainQuery computes the facet over the docs matching the queries instead of the parent domain
+func (f *JSONFacet) DomainQuery(queries ...string) *JSONFacet {
+	return f.domain("query", queries)
+}
+
+// BlockParent switches the domain from child docs to their parents matching which
+func (f *JSONFacet) BlockParent(which string) *JSONFacet {
+	return f.domain("blockParent", which)
+}
+
+// BlockChildren switches the domain from parent docs matching of to their children
+func (f *JSONFacet) BlockChildren(of string) *JSONFacet {
+	return f.domain("blockChildren", of)
+}
+
+// Join switches the domain to the docs whose to field matches the from field of the domain
+func (f *JSONFacet) Join(from string, to string) *JSONFacet {
+	return f.domain("join", map[string]string{"from": from, "to": to})
+}
+
+func (f *JSONFacet) domain(name string, value interface{}) *JSONFacet {
+	domain, ok := f.params["domain"].(map[string]interface{})
+	if !ok {
+		domain = make(map[string]interface{})
+		f.params["domain"] = domain
+	}
+	domain[name] = value
+	return f
+}
+
+// Facet adds a named sub facet computed over the docs of every bucket
+func (f *JSONFacet) Facet(name string, facet *JSONFacet) *JSONFacet {
+	f.facets[name] = facet
+	return f
+}
+
+// Stat adds a named aggregation computed over the docs of every bucket, like AggAvg("price")
+func (f *JSONFacet) Stat(name string, aggregation string) *JSONFacet {
+	f.facets[name] = aggregation
+	return f
+}
+
+func (f *JSONFacet) MarshalJSON() ([]byte, error) {
+	if f.root {
+		return json.Marshal(f.facets)
+	}
+	m := make(map[string]interface{}, len(f.params)+1)
+	for k, v := range f.params {
+		m[k] = v
+	}
+	if len(f.facets) > 0 {
+		m["facet"] = f.facets
+	}
+	return json.Marshal(m)
+}
+
+// JSONFacets sets the json.facet param to the facets. The facets are encoded right away so a param
+// json cannot encode, like a NaN or a func, is returned as an error instead of dropping the facets
+func JSONFacets(facets *JSONFacet) (func(url.Values), error) {
+	b, err := json.Marshal(facets)
+	if err != nil {
+		return nil, fmt.Errorf("[go-solr] json facets: %v", err)
+	}
+	return func(p url.Values) {
+		p.Set("json.facet", string(b))
+	}, nil
+}
+
+func aggregation(function string, args ...string) string {
+	return function + "(" + strings.Join(args, ",") + ")"
+}
+
+// AggSum sums field
+func AggSum(field string) string { return aggregation("sum", field) }
+
+// AggAvg averages field
+func AggAvg(field string) string { return aggregation("avg", field) }
+
+// AggMin is the min of field
+func AggMin(field string) string { return aggregation("min", field) }
+
+// AggMax is the max of field
+func AggMax(field string) string { return aggregation("max", field) }
+
+// AggUnique counts the distinct values of field
+func AggUnique(field string) string { return aggregation("unique", field) }
+
+// AggHLL estimates the distinct values of field with hyper-log-log
+func AggHLL(field string) string { return aggregation("hll", field) }
+
+// AggCountValues counts the values of a multi-valued field
+func AggCountValues(field string) string { return aggregation("countvals", field) }
+
+// AggPercentile estimates the percentiles of field, the stat is a list when more than one is asked
+func AggPercentile(field string, percentiles ...float64) string {
+	args := []string{field}
+	for _, p := range percentiles {
+		args = append(args, strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	return aggregation("percentile", args...)
+}
+
+// FacetBucket is a bucket of the facets section, the section itself is the top level bucket. Stats
+// are in Metrics, terms and range sub facets in Facets, query sub facets in Queries and heatmaps in Heatmaps
+type FacetBucket struct {
+	Val      interface{}
+	Count    int64
+	Metrics  map[string]interface{}
+	Facets   map[string]BucketFacet
+	Queries  map[string]FacetBucket
+	Heatmaps map[string]HeatmapFacet
+}
+
+// BucketFacet is the result of a terms or range facet
+type BucketFacet struct {
+	Buckets    []FacetBucket `json:"buckets"`
+	NumBuckets int64         `json:"numBuckets"`
+	AllBuckets *FacetBucket  `json:"allBuckets"`
+	Missing    *FacetBucket  `json:"missing"`
+	Before     *FacetBucket  `json:"before"`
+	After      *FacetBucket  `json:"after"`
+	Between    *FacetBucket  `json:"between"`
+}
+
+// HeatmapFacet is the grid of a heatmap facet, CountsInts2D is nil where a row has no docs
+type HeatmapFacet struct {
+	GridLevel    int     `json:"gridLevel"`
+	Columns      int     `json:"columns"`
+	Rows         int     `json:"rows"`
+	MinX         float64 `json:"minX"`
+	MaxX         float64 `json:"maxX"`
+	MinY         float64 `json:"minY"`
+	MaxY         float64 `json:"maxY"`
+	CountsInts2D [][]int `json:"counts_ints2D"`
+	CountsPNG    string  `json:"counts_png"`
+}
+
+func (b *FacetBucket) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	bucket := FacetBucket{}
+	for name, raw := range fields {
+		var err error
+		switch name {
+		case "val":
+			err = json.Unmarshal(raw, &bucket.Val)
+		case "count":
+			err = json.Unmarshal(raw, &bucket.Count)
+		default:
+			err = bucket.unmarshalEntry(name, raw)
+		}
+		if err != nil {
+			return fmt.Errorf("[go-solr] facets: %s: %v", name, err)
+		}
+	}
+	*b = bucket
+	return nil
+}
+
+// unmarshalEntry tells the sub facets from the stats by the keys of their objects
+func (b *FacetBucket) unmarshalEntry(name string, raw json.RawMessage) error {
+	var keys map[string]json.RawMessage
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '{' || json.Unmarshal(raw, &keys) != nil {
+		keys = nil
+	}
+	switch {
+	case keys == nil || keys["relatedness"] != nil:
+		var metric interface{}
+		if err := json.Unmarshal(raw, &metric); err != nil {
+			return err
+		}
+		if b.Metrics == nil {
+			b.Metrics = make(map[string]interface{})
+		}
+		b.Metrics[name] = metric
+	case keys["buckets"] != nil:
+		var facet BucketFacet
+		if err := json.Unmarshal(raw, &facet); err != nil {
+			return err
+		}
+		if b.Facets == nil {
+			b.Facets = make(map[string]BucketFacet)
+		}
+		b.Facets[name] = facet
+	case keys["gridLevel"] != nil:
+		var heatmap HeatmapFacet
+		if err := json.Unmarshal(raw, &heatmap); err != nil {
+			return err
+		}
+		if b.Heatmaps == nil {
+			b.Heatmaps = make(map[string]HeatmapFacet)
+		}
+		b.Heatmaps[name] = heatmap
+	default:
+		var query FacetBucket
+		if err := json.Unmarshal(raw, &query); err != nil {
+			return err
+		}
+		if b.Queries == nil {
+			b.Queries = make(map[string]FacetBucket)
+		}
+		b.Queries[name] = query
+	}
+	return nil
+}
+
+// Metric returns a numeric stat of the bucket, false when it is missing or not a number
+func (b FacetBucket) Metric(name string) (float64, bool) {
+	f, err := toFloat64(b.Metrics[name])
+	return f, err == nil
+}
@@ -0,0 +1,109 @@
+package solr_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("JSON Facets", func() {
+	It("reports facets json cannot encode", func() {
+		jsonFacets, err := solr.JSONFacets(solr.NewJSONFacets().Facet("prices", solr.NewTermsFacet("price").Param("offset", math.NaN())))
+		Expect(err).NotTo(BeNil())
+		Expect(jsonFacets).To(BeNil())
+	})
+
+	It("builds the json.facet param", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		facets := solr.NewJSONFacets().
+			Stat("total", solr.AggSum("price")).
+			Facet("cats", solr.NewTermsFacet("cat").Limit(5).Sort("avg_price desc").ExcludeTags("dt").
+				Stat("avg_price", solr.AggAvg("price")).
+				Stat("p", solr.AggPercentile("price", 50, 99.9)).
+				Facet("stock", solr.NewQueryFacet("inStock:true"))).
+			Facet("prices", solr.NewRangeFacet("price", 0, 100, 50).Other("all")).
+			Facet("parents", solr.NewTermsFacet("type").BlockParent("type:parent"))
+		jsonFacets, err := solr.JSONFacets(facets)
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"), jsonFacets)
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		var sent map[string]interface{}
+		Expect(json.Unmarshal([]byte(params.Get("json.facet")), &sent)).To(BeNil())
+		Expect(sent).To(Equal(map[string]interface{}{
+			"total": "sum(price)",
+			"cats": map[string]interface{}{
+				"type": "terms", "field": "cat", "limit": 5.0, "sort": "avg_price desc",
+				"domain": map[string]interface{}{"excludeTags": []interface{}{"dt"}},
+				"facet": map[string]interface{}{
+					"avg_price": "avg(price)",
+					"p":         "percentile(price,50,99.9)",
+					"stock":     map[string]interface{}{"type": "query", "q": "inStock:true"},
+				},
+			},
+			"prices": map[string]interface{}{
+				"type": "range", "field": "price", "start": 0.0, "end": 100.0, "gap": 50.0,
+				"other": []interface{}{"all"},
+			},
+			"parents": map[string]interface{}{
+				"type": "terms", "field": "type",
+				"domain": map[string]interface{}{"blockParent": "type:parent"},
+			},
+		}))
+	})
+
+	It("decodes buckets, stats and nested facets", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"response":{"numFound":32,"start":0,"docs":[]},
+			"facets":{
+				"count":32,
+				"total":1234.5,
+				"cats":{"numBuckets":2,"buckets":[
+					{"val":"electronics","count":12,"avg_price":99.5,"p":[10.0,300.0],"stock":{"count":10,"max_price":250.0}},
+					{"val":"memory","count":3,"avg_price":50.0,"p":[20.0,80.0],"stock":{"count":0}}],
+					"missing":{"count":1}},
+				"prices":{"buckets":[{"val":0,"count":20},{"val":50,"count":10}],"before":{"count":0},"after":{"count":2},"between":{"count":30}},
+				"grid":{"gridLevel":1,"columns":2,"rows":1,"minX":-180.0,"maxX":180.0,"minY":-90.0,"maxY":90.0,"counts_ints2D":[[3,0]]}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		facets := r.Facets
+		Expect(facets.Count).To(Equal(int64(32)))
+		total, ok := facets.Metric("total")
+		Expect(ok).To(BeTrue())
+		Expect(total).To(Equal(1234.5))
+
+		cats := facets.Facets["cats"]
+		Expect(cats.NumBuckets).To(Equal(int64(2)))
+		Expect(cats.Missing.Count).To(Equal(int64(1)))
+		Expect(cats.Buckets).To(HaveLen(2))
+		electronics := cats.Buckets[0]
+		Expect(electronics.Val).To(Equal("electronics"))
+		Expect(electronics.Count).To(Equal(int64(12)))
+		Expect(electronics.Metrics["p"]).To(Equal([]interface{}{10.0, 300.0}))
+		_, ok = electronics.Metric("p")
+		Expect(ok).To(BeFalse())
+		Expect(electronics.Queries["stock"].Count).To(Equal(int64(10)))
+		maxPrice, _ := electronics.Queries["stock"].Metric("max_price")
+		Expect(maxPrice).To(Equal(250.0))
+
+		prices := facets.Facets["prices"]
+		Expect(prices.Buckets[1].Val).To(Equal(50.0))
+		Expect(prices.Between.Count).To(Equal(int64(30)))
+		Expect(prices.Before.Count).To(Equal(int64(0)))
+
+		Expect(facets.Heatmaps["grid"].Columns).To(Equal(2))
+		Expect(facets.Heatmaps["grid"].CountsInts2D).To(Equal([][]int{{3, 0}}))
+	})
+})
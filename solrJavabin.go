@@ -0,0 +1,1012 @@
+package solr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// javabin tags, see org.apache.solr.common.util.JavaBinCodec
+const (
+	javabinVersion = 2
+
+	jbNull           = 0
+	jbTrue           = 1
+	jbFalse          = 2
+	jbByte           = 3
+	jbShort          = 4
+	jbDouble         = 5
+	jbInt            = 6
+	jbLong           = 7
+	jbFloat          = 8
+	jbDate           = 9
+	jbMap            = 10
+	jbSolrDoc        = 11
+	jbSolrDocList    = 12
+	jbByteArr        = 13
+	jbIterator       = 14
+	jbEnd            = 15
+	jbSolrInputDoc   = 16
+	jbMapEntryIter   = 17
+	jbEnumFieldValue = 18
+	jbMapEntry       = 19
+
+	// tags carrying a size or a small value in their low 5 bits
+	jbStr          = 1 << 5
+	jbSint         = 2 << 5
+	jbSlong        = 3 << 5
+	jbArr          = 4 << 5
+	jbOrderedMap   = 5 << 5
+	jbNamedList    = 6 << 5
+	jbExternString = 7 << 5
+)
+
+// WireFormat is the format of the bodies a client exchanges with solr
+type WireFormat int
+
+const (
+	// WireJSON is the default format
+	WireJSON WireFormat = iota
+	// WireJavabin is solr's binary format, used for the select responses and update bodies.
+	// The other handlers, SelectInto and SelectStream keep answering json
+	WireJavabin
+)
+
+const (
+	javabinContentType      = "application/javabin"
+	javabinResponseMimeType = "application/octet-stream"
+)
+
+const childDocumentsField = "_childDocuments_"
+
+var errJavabinEnd = errors.New("[go-solr] javabin: unexpected end marker")
+
+// javabinMaxDepth bounds the nesting of the values of a body, so a corrupt body errors before the stack blows up
+const javabinMaxDepth = 1000
+
+// javabinPrealloc bounds what is allocated from a size read off the wire, the rest grows as the values are read
+const javabinPrealloc = 1024
+
+// namedList is solr's NamedList, ordered lists are SimpleOrderedMaps which solr writes as json objects
+// while plain named lists are written as flat [name, value, ...] arrays
+type namedList struct {
+	ordered bool
+	entries []namedEntry
+}
+
+type namedEntry struct {
+	name  string
+	value interface{}
+}
+
+// solrDocument is a doc of a response, solrInputDocument a doc sent for indexing
+type solrDocument map[string]interface{}
+
+type solrInputDocument map[string]interface{}
+
+type solrDocList struct {
+	numFound int64
+	start    int64
+	maxScore interface{}
+	docs     []solrDocument
+}
+
+// javabinIterator is written as an ITERATOR, the values are streamed until an END marker
+type javabinIterator []interface{}
+
+// javabinEnd is the value read for an END marker
+type javabinEnd struct{}
+
+type javabinDecoder struct {
+	r       *bufio.Reader
+	externs []string
+	depth   int
+}
+
+func newJavabinDecoder(r io.Reader) *javabinDecoder {
+	return &javabinDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the version byte and the value that follows it
+func (d *javabinDecoder) Decode() (interface{}, error) {
+	version, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != javabinVersion {
+		return nil, fmt.Errorf("[go-solr] javabin: unsupported version %d", version)
+	}
+	v, err := d.readVal()
+	if _, ok := v.(javabinEnd); ok {
+		return nil, errJavabinEnd
+	}
+	return v, err
+}
+
+func (d *javabinDecoder) readVal() (interface{}, error) {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if d.depth++; d.depth > javabinMaxDepth {
+		return nil, fmt.Errorf("[go-solr] javabin: values nested deeper than %d", javabinMaxDepth)
+	}
+	defer func() { d.depth-- }()
+	return d.readTagged(tag)
+}
+
+// unexpectedEOF is io.ErrUnexpectedEOF for a body that ends in the middle of a value
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (d *javabinDecoder) readTagged(tag byte) (interface{}, error) {
+	switch tag & 0xe0 {
+	case jbStr:
+		return d.readStr(tag)
+	case jbSint:
+		v, err := d.readSmall(tag)
+		return int32(v), err
+	case jbSlong:
+		return d.readSmall(tag)
+	case jbArr:
+		size, err := d.readSize(tag)
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(size)
+	case jbOrderedMap, jbNamedList:
+		size, err := d.readSize(tag)
+		if err != nil {
+			return nil, err
+		}
+		return d.readNamedList(size, tag&0xe0 == jbOrderedMap)
+	case jbExternString:
+		return d.readExternString(tag)
+	}
+
+	switch tag {
+	case jbNull:
+		return nil, nil
+	case jbTrue:
+		return true, nil
+	case jbFalse:
+		return false, nil
+	case jbByte:
+		b, err := d.r.ReadByte()
+		return int8(b), err
+	case jbShort:
+		var v int16
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case jbInt:
+		var v int32
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case jbLong:
+		var v int64
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case jbFloat:
+		var v float32
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case jbDouble:
+		var v float64
+		err := binary.Read(d.r, binary.BigEndian, &v)
+		return v, err
+	case jbDate:
+		var ms int64
+		if err := binary.Read(d.r, binary.BigEndian, &ms); err != nil {
+			return nil, err
+		}
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	case jbMap:
+		size, err := d.readVSize()
+		if err != nil {
+			return nil, err
+		}
+		return d.readMap(size)
+	case jbMapEntryIter:
+		return d.readMap(-1)
+	case jbMapEntry:
+		return d.readMap(1)
+	case jbSolrDoc:
+		return d.readSolrDoc()
+	case jbSolrDocList:
+		return d.readSolrDocList()
+	case jbByteArr:
+		size, err := d.readVSize()
+		if err != nil {
+			return nil, err
+		}
+		return d.readBytes(size)
+	case jbIterator:
+		var values []interface{}
+		for {
+			v, err := d.readVal()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := v.(javabinEnd); ok {
+				return values, nil
+			}
+			values = append(values, v)
+		}
+	case jbEnd:
+		return javabinEnd{}, nil
+	case jbSolrInputDoc:
+		return d.readSolrInputDoc()
+	case jbEnumFieldValue:
+		// the int value of the enum is dropped, its string value is what the json writer returns
+		if _, err := d.readVal(); err != nil {
+			return nil, err
+		}
+		return d.readVal()
+	}
+	return nil, fmt.Errorf("[go-solr] javabin: unknown tag %d", tag)
+}
+
+// readSize reads the size carried by the low 5 bits of tag, continued by a vint when they are all set
+func (d *javabinDecoder) readSize(tag byte) (int, error) {
+	size := uint64(tag & 0x1f)
+	if size == 0x1f {
+		n, err := d.readVInt()
+		if err != nil {
+			return 0, err
+		}
+		if n > math.MaxInt32 {
+			return 0, fmt.Errorf("[go-solr] javabin: size %d is out of range", n)
+		}
+		size += n
+	}
+	return javabinSize(size)
+}
+
+// readVSize reads a size written as a vint
+func (d *javabinDecoder) readVSize() (int, error) {
+	n, err := d.readVInt()
+	if err != nil {
+		return 0, err
+	}
+	return javabinSize(n)
+}
+
+// javabinSize checks a size read off the wire, solr writes java ints so anything larger is a corrupt body
+func javabinSize(n uint64) (int, error) {
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("[go-solr] javabin: size %d is out of range", n)
+	}
+	return int(n), nil
+}
+
+// readBytes reads size bytes, the buffer grows as they arrive so a corrupt size does not allocate them upfront
+func (d *javabinDecoder) readBytes(size int) ([]byte, error) {
+	var buf bytes.Buffer
+	if size < javabinPrealloc {
+		buf.Grow(size)
+	}
+	if _, err := io.CopyN(&buf, d.r, int64(size)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *javabinDecoder) readSmall(tag byte) (int64, error) {
+	v := int64(tag & 0x0f)
+	if tag&0x10 != 0 {
+		n, err := d.readVInt()
+		if err != nil {
+			return 0, err
+		}
+		v |= int64(n) << 4
+	}
+	return v, nil
+}
+
+func (d *javabinDecoder) readVInt() (uint64, error) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("[go-solr] javabin: variable length int overflows")
+}
+
+func (d *javabinDecoder) readStr(tag byte) (string, error) {
+	size, err := d.readSize(tag)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.readBytes(size)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *javabinDecoder) readExternString(tag byte) (string, error) {
+	idx, err := d.readSize(tag)
+	if err != nil {
+		return "", err
+	}
+	if idx != 0 {
+		if idx > len(d.externs) {
+			return "", fmt.Errorf("[go-solr] javabin: unknown extern string %d", idx)
+		}
+		return d.externs[idx-1], nil
+	}
+	strTag, err := d.r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	s, err := d.readStr(strTag)
+	if err != nil {
+		return "", err
+	}
+	d.externs = append(d.externs, s)
+	return s, nil
+}
+
+func (d *javabinDecoder) readArray(size int) ([]interface{}, error) {
+	values := make([]interface{}, 0, preallocSize(size))
+	for i := 0; i < size; i++ {
+		v, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *javabinDecoder) readNamedList(size int, ordered bool) (*namedList, error) {
+	nl := &namedList{ordered: ordered, entries: make([]namedEntry, 0, preallocSize(size))}
+	for i := 0; i < size; i++ {
+		name, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		var entry namedEntry
+		if name != nil {
+			entry.name = fmt.Sprint(name)
+		}
+		entry.value = value
+		nl.entries = append(nl.entries, entry)
+	}
+	return nl, nil
+}
+
+// preallocSize is the capacity to allocate for size values read off the wire
+func preallocSize(size int) int {
+	if size > javabinPrealloc {
+		return javabinPrealloc
+	}
+	return size
+}
+
+// readMap reads size entries, or entries up to an END marker when size is negative
+func (d *javabinDecoder) readMap(size int) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for i := 0; size < 0 || i < size; i++ {
+		k, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.(javabinEnd); ok && size < 0 {
+			break
+		}
+		v, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
+
+func (d *javabinDecoder) readSolrDoc() (solrDocument, error) {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	size, err := d.readSize(tag)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(solrDocument, preallocSize(size))
+	for i := 0; i < size; i++ {
+		name, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		if child, ok := name.(solrDocument); ok {
+			children, _ := doc[childDocumentsField].([]interface{})
+			doc[childDocumentsField] = append(children, child)
+			continue
+		}
+		value, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		doc[fmt.Sprint(name)] = value
+	}
+	return doc, nil
+}
+
+func (d *javabinDecoder) readSolrDocList() (*solrDocList, error) {
+	header, err := d.readVal()
+	if err != nil {
+		return nil, err
+	}
+	values, ok := header.([]interface{})
+	if !ok || len(values) < 3 {
+		return nil, fmt.Errorf("[go-solr] javabin: invalid doc list header %v", header)
+	}
+	dl := &solrDocList{maxScore: values[2]}
+	if dl.numFound, err = toInt64(javabinNumber(values[0])); err != nil {
+		return nil, err
+	}
+	if dl.start, err = toInt64(javabinNumber(values[1])); err != nil {
+		return nil, err
+	}
+	docs, err := d.readVal()
+	if err != nil {
+		return nil, err
+	}
+	list, _ := docs.([]interface{})
+	for _, doc := range list {
+		if doc, ok := doc.(solrDocument); ok {
+			dl.docs = append(dl.docs, doc)
+		}
+	}
+	return dl, nil
+}
+
+func (d *javabinDecoder) readSolrInputDoc() (solrInputDocument, error) {
+	size, err := d.readVSize()
+	if err != nil {
+		return nil, err
+	}
+	// the doc boost solr still writes
+	if _, err := d.readVal(); err != nil {
+		return nil, err
+	}
+	doc := make(solrInputDocument, preallocSize(size))
+	for i := 0; i < size; i++ {
+		name, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		if child, ok := name.(solrInputDocument); ok {
+			children, _ := doc[childDocumentsField].([]interface{})
+			doc[childDocumentsField] = append(children, map[string]interface{}(child))
+			continue
+		}
+		value, err := d.readVal()
+		if err != nil {
+			return nil, err
+		}
+		doc[fmt.Sprint(name)] = value
+	}
+	return doc, nil
+}
+
+type javabinEncoder struct {
+	w       *bufio.Writer
+	externs map[string]int
+}
+
+func newJavabinEncoder(w io.Writer) *javabinEncoder {
+	return &javabinEncoder{w: bufio.NewWriter(w), externs: make(map[string]int)}
+}
+
+// Encode writes the version byte followed by v
+func (e *javabinEncoder) Encode(v interface{}) error {
+	if err := e.w.WriteByte(javabinVersion); err != nil {
+		return err
+	}
+	if err := e.writeVal(v); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *javabinEncoder) writeVal(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		return e.w.WriteByte(jbNull)
+	case bool:
+		if v {
+			return e.w.WriteByte(jbTrue)
+		}
+		return e.w.WriteByte(jbFalse)
+	case string:
+		return e.writeStr(v)
+	case int8:
+		return e.writeInt(int32(v))
+	case int16:
+		return e.writeInt(int32(v))
+	case int32:
+		return e.writeInt(v)
+	case int:
+		return e.writeLong(int64(v))
+	case int64:
+		return e.writeLong(v)
+	case uint8:
+		return e.writeInt(int32(v))
+	case uint16:
+		return e.writeInt(int32(v))
+	case uint32:
+		return e.writeLong(int64(v))
+	case float32:
+		return e.writeFixed(jbFloat, math.Float32bits(v))
+	case float64:
+		return e.writeFixed(jbDouble, math.Float64bits(v))
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return e.writeLong(i)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		return e.writeFixed(jbDouble, math.Float64bits(f))
+	case time.Time:
+		return e.writeFixed(jbDate, v.UnixNano()/int64(time.Millisecond))
+	case []byte:
+		e.w.WriteByte(jbByteArr)
+		e.writeVInt(uint64(len(v)))
+		_, err := e.w.Write(v)
+		return err
+	case map[string]interface{}:
+		e.w.WriteByte(jbMap)
+		e.writeVInt(uint64(len(v)))
+		for k, value := range v {
+			if err := e.writeExternString(k); err != nil {
+				return err
+			}
+			if err := e.writeVal(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *namedList:
+		tag := byte(jbNamedList)
+		if v.ordered {
+			tag = jbOrderedMap
+		}
+		e.writeTag(tag, len(v.entries))
+		for _, entry := range v.entries {
+			if err := e.writeExternString(entry.name); err != nil {
+				return err
+			}
+			if err := e.writeVal(entry.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case solrDocument:
+		return e.writeSolrDoc(v)
+	case *solrDocList:
+		e.w.WriteByte(jbSolrDocList)
+		if err := e.writeVal([]interface{}{v.numFound, v.start, v.maxScore}); err != nil {
+			return err
+		}
+		e.writeTag(jbArr, len(v.docs))
+		for _, doc := range v.docs {
+			if err := e.writeSolrDoc(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	case solrInputDocument:
+		return e.writeSolrInputDoc(v)
+	case javabinIterator:
+		e.w.WriteByte(jbIterator)
+		for _, value := range v {
+			if err := e.writeVal(value); err != nil {
+				return err
+			}
+		}
+		return e.w.WriteByte(jbEnd)
+	case []interface{}:
+		e.writeTag(jbArr, len(v))
+		for _, value := range v {
+			if err := e.writeVal(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		e.writeTag(jbArr, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.writeVal(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return e.w.WriteByte(jbNull)
+		}
+	}
+	// anything else is written the way it encodes to json
+	generic, err := jsonRoundTrip(v)
+	if err != nil {
+		return err
+	}
+	return e.writeVal(generic)
+}
+
+func (e *javabinEncoder) writeTag(tag byte, size int) {
+	if tag&0xe0 == 0 {
+		e.w.WriteByte(tag)
+		e.writeVInt(uint64(size))
+		return
+	}
+	if size < 0x1f {
+		e.w.WriteByte(tag | byte(size))
+		return
+	}
+	e.w.WriteByte(tag | 0x1f)
+	e.writeVInt(uint64(size - 0x1f))
+}
+
+func (e *javabinEncoder) writeVInt(v uint64) {
+	for v&^0x7f != 0 {
+		e.w.WriteByte(byte(v&0x7f) | 0x80)
+		v >>= 7
+	}
+	e.w.WriteByte(byte(v))
+}
+
+func (e *javabinEncoder) writeFixed(tag byte, v interface{}) error {
+	e.w.WriteByte(tag)
+	return binary.Write(e.w, binary.BigEndian, v)
+}
+
+func (e *javabinEncoder) writeInt(v int32) error {
+	if v < 0 {
+		return e.writeFixed(jbInt, v)
+	}
+	e.writeSmall(jbSint, uint64(v))
+	return nil
+}
+
+func (e *javabinEncoder) writeLong(v int64) error {
+	if uint64(v)&0xff00000000000000 != 0 {
+		return e.writeFixed(jbLong, v)
+	}
+	e.writeSmall(jbSlong, uint64(v))
+	return nil
+}
+
+func (e *javabinEncoder) writeSmall(tag byte, v uint64) {
+	if v&^0x0f == 0 {
+		e.w.WriteByte(tag | byte(v))
+		return
+	}
+	e.w.WriteByte(tag | 0x10 | byte(v&0x0f))
+	e.writeVInt(v >> 4)
+}
+
+func (e *javabinEncoder) writeStr(s string) error {
+	e.writeTag(jbStr, len(s))
+	_, err := e.w.WriteString(s)
+	return err
+}
+
+// writeExternString writes s the first time and its index afterwards, solr does so for field names
+func (e *javabinEncoder) writeExternString(s string) error {
+	if idx, ok := e.externs[s]; ok {
+		e.writeTag(jbExternString, idx)
+		return nil
+	}
+	e.writeTag(jbExternString, 0)
+	e.externs[s] = len(e.externs) + 1
+	return e.writeStr(s)
+}
+
+func (e *javabinEncoder) writeSolrDoc(doc solrDocument) error {
+	children := childDocuments(doc)
+	size := len(doc) + len(children)
+	if _, ok := doc[childDocumentsField]; ok {
+		size--
+	}
+	e.w.WriteByte(jbSolrDoc)
+	e.writeTag(jbOrderedMap, size)
+	for name, value := range doc {
+		if name == childDocumentsField {
+			continue
+		}
+		if err := e.writeExternString(name); err != nil {
+			return err
+		}
+		if err := e.writeVal(value); err != nil {
+			return err
+		}
+	}
+	for _, child := range children {
+		if err := e.writeSolrDoc(solrDocument(child)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *javabinEncoder) writeSolrInputDoc(doc solrInputDocument) error {
+	children := childDocuments(doc)
+	size := len(doc) + len(children)
+	if _, ok := doc[childDocumentsField]; ok {
+		size--
+	}
+	e.writeTag(jbSolrInputDoc, size)
+	// the doc boost solr still reads
+	if err := e.writeFixed(jbFloat, math.Float32bits(1)); err != nil {
+		return err
+	}
+	for name, value := range doc {
+		if name == childDocumentsField {
+			continue
+		}
+		if err := e.writeExternString(name); err != nil {
+			return err
+		}
+		if err := e.writeVal(value); err != nil {
+			return err
+		}
+	}
+	for _, child := range children {
+		if err := e.writeSolrInputDoc(solrInputDocument(child)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childDocuments returns the nested docs of the _childDocuments_ field
+func childDocuments(doc map[string]interface{}) []map[string]interface{} {
+	var children []map[string]interface{}
+	switch v := doc[childDocumentsField].(type) {
+	case []map[string]interface{}:
+		children = v
+	case []interface{}:
+		for _, child := range v {
+			switch child := child.(type) {
+			case map[string]interface{}:
+				children = append(children, child)
+			case solrDocument:
+				children = append(children, child)
+			case solrInputDocument:
+				children = append(children, child)
+			}
+		}
+	}
+	return children
+}
+
+func jsonRoundTrip(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	err = dec.Decode(&generic)
+	return generic, err
+}
+
+// javabinUpdateRequest builds the request solr's javabin update loader reads. Deletes are sent
+// as delById and delByQ, anything else as the docs to add, one doc or a slice of docs
+func javabinUpdateRequest(doc interface{}) (*namedList, error) {
+	req := &namedList{entries: []namedEntry{{name: "params", value: &namedList{}}}}
+	switch d := doc.(type) {
+	case DeleteRequest:
+		return appendEntry(req, "delById", d.Delete), nil
+	case deleteQueryRequest:
+		return appendEntry(req, "delByQ", []string{d.Delete.Query}), nil
+	}
+
+	var docs javabinIterator
+	rv := reflect.ValueOf(doc)
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			inputDoc, err := toInputDoc(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, inputDoc)
+		}
+	} else {
+		inputDoc, err := toInputDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, inputDoc)
+	}
+	return appendEntry(req, "docs", docs), nil
+}
+
+func appendEntry(nl *namedList, name string, value interface{}) *namedList {
+	nl.entries = append(nl.entries, namedEntry{name: name, value: value})
+	return nl
+}
+
+func toInputDoc(doc interface{}) (solrInputDocument, error) {
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		return solrInputDocument(d), nil
+	case *AtomicUpdate:
+		return solrInputDocument(d.Doc()), nil
+	}
+	generic, err := jsonRoundTrip(doc)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := generic.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[go-solr] javabin: %T does not encode to a doc", doc)
+	}
+	return solrInputDocument(m), nil
+}
+
+// decodeJavabinResponse fills sr from a javabin select response. The docs are converted to the
+// values the json response has, other sections are remarshalled to json and unmarshalled into sr
+func decodeJavabinResponse(r io.Reader, sr *SolrResponse) error {
+	v, err := newJavabinDecoder(r).Decode()
+	if err != nil {
+		return err
+	}
+	nl, ok := v.(*namedList)
+	if !ok {
+		return fmt.Errorf("[go-solr] javabin: response is a %T, not a named list", v)
+	}
+	rest := make(map[string]interface{}, len(nl.entries))
+	for _, entry := range nl.entries {
+		if dl, ok := entry.value.(*solrDocList); ok && entry.name == "response" {
+			sr.Response = Response{NumFound: uint32(dl.numFound), Start: int(dl.start)}
+			for _, doc := range dl.docs {
+				sr.Response.Docs = append(sr.Response.Docs, jsonDoc(doc))
+			}
+			continue
+		}
+		rest[entry.name] = jsonValue(entry.value)
+	}
+	b, err := json.Marshal(rest)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, sr)
+}
+
+// javabinToJSON converts a javabin body, like the error bodies solr answers javabin requests with, to json
+func javabinToJSON(body []byte) ([]byte, error) {
+	v, err := newJavabinDecoder(bytes.NewReader(body)).Decode()
+	if err != nil {
+		return nil, err
+	}
+	if nl, ok := v.(*namedList); ok {
+		nl.ordered = true
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+// jsonValue converts a decoded value to one that marshals to the json solr would have written
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *namedList:
+		if v.ordered {
+			m := make(map[string]interface{}, len(v.entries))
+			for _, entry := range v.entries {
+				m[entry.name] = jsonValue(entry.value)
+			}
+			return m
+		}
+		flat := make([]interface{}, 0, 2*len(v.entries))
+		for _, entry := range v.entries {
+			flat = append(flat, entry.name, jsonValue(entry.value))
+		}
+		return flat
+	case solrDocument:
+		return jsonDoc(v)
+	case *solrDocList:
+		docs := make([]interface{}, len(v.docs))
+		for i, doc := range v.docs {
+			docs[i] = jsonDoc(doc)
+		}
+		return map[string]interface{}{"numFound": v.numFound, "start": v.start, "maxScore": v.maxScore, "docs": docs}
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = jsonValue(v[i])
+		}
+		return values
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[k] = jsonValue(value)
+		}
+		return m
+	}
+	return v
+}
+
+// jsonDoc converts the field values of a doc to the ones json decoding gives: numbers are float64,
+// dates RFC3339 strings and binary fields base64 strings. The _version_ stays an exact int64
+func jsonDoc(doc solrDocument) map[string]interface{} {
+	m := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		if version, ok := v.(int64); ok && k == versionField {
+			m[k] = version
+			continue
+		}
+		m[k] = jsonDocValue(v)
+	}
+	return m
+}
+
+func jsonDocValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int8, int16, int32, int64, float32:
+		f, _ := toFloat64(javabinNumber(v))
+		return f
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case solrDocument:
+		return jsonDoc(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = jsonDocValue(v[i])
+		}
+		return values
+	}
+	return jsonValue(v)
+}
+
+// javabinNumber widens the javabin numbers to the types toInt64 and toFloat64 take, a float
+// becomes the float64 of its shortest representation as solr writes it in json
+func javabinNumber(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+		return f
+	}
+	return v
+}
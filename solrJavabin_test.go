@@ -0,0 +1,132 @@
+package solr
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type javabinHTTPer struct {
+	body     []byte
+	requests []*http.Request
+}
+
+func (f *javabinHTTPer) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {javabinResponseMimeType}},
+		Body:       ioutil.NopCloser(bytes.NewReader(f.body)),
+	}, nil
+}
+
+func javabinBytes(v interface{}) []byte {
+	var buf bytes.Buffer
+	Expect(newJavabinEncoder(&buf).Encode(v)).To(BeNil())
+	return buf.Bytes()
+}
+
+var _ = Describe("Javabin", func() {
+	It("encodes small values the way solr does", func() {
+		Expect(javabinBytes(int32(5))).To(Equal([]byte{2, 0x45}))
+		Expect(javabinBytes(int32(300))).To(Equal([]byte{2, 0x5c, 0x12}))
+		Expect(javabinBytes(int32(-1))).To(Equal([]byte{2, 6, 0xff, 0xff, 0xff, 0xff}))
+		Expect(javabinBytes(int64(1))).To(Equal([]byte{2, 0x61}))
+		Expect(javabinBytes("id")).To(Equal([]byte{2, 0x22, 'i', 'd'}))
+		Expect(javabinBytes([]interface{}{true, nil})).To(Equal([]byte{2, 0x82, 1, 0}))
+	})
+
+	It("round trips named lists, doc lists, dates, byte arrays and iterators", func() {
+		date := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+		long := "a string longer than thirty one bytes to need a vint"
+		in := &namedList{entries: []namedEntry{
+			{name: "responseHeader", value: &namedList{ordered: true, entries: []namedEntry{{name: "status", value: int32(0)}}}},
+			{name: "response", value: &solrDocList{numFound: 1, start: 0, maxScore: float32(1.5), docs: []solrDocument{
+				{"id": "1", "created": date, "blob": []byte{1, 2}, "_version_": int64(1603386011406549001), "tags": []interface{}{long, "b"}},
+			}}},
+			{name: "iter", value: javabinIterator{int64(-7), 2.5}},
+		}}
+		out, err := newJavabinDecoder(bytes.NewReader(javabinBytes(in))).Decode()
+		Expect(err).To(BeNil())
+		nl := out.(*namedList)
+		Expect(nl.entries[0].value).To(Equal(in.entries[0].value))
+		docList := nl.entries[1].value.(*solrDocList)
+		Expect(docList.numFound).To(Equal(int64(1)))
+		Expect(docList.maxScore).To(Equal(float32(1.5)))
+		Expect(docList.docs[0]).To(Equal(in.entries[1].value.(*solrDocList).docs[0]))
+		Expect(nl.entries[2].value).To(Equal([]interface{}{int64(-7), 2.5}))
+	})
+
+	It("decodes a select response into the values the json response has", func() {
+		cli := &javabinHTTPer{body: javabinBytes(&namedList{ordered: true, entries: []namedEntry{
+			{name: "responseHeader", value: &namedList{ordered: true, entries: []namedEntry{{name: "status", value: int32(0)}, {name: "QTime", value: int32(4)}}}},
+			{name: "response", value: &solrDocList{numFound: 42, start: 10, docs: []solrDocument{
+				{"id": "1", "count": int32(3), "score": float32(1.1), "created": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "_version_": int64(1603386011406549001)},
+			}}},
+			{name: "nextCursorMark", value: "AoE"},
+		}})}
+		s, err := NewSolrHTTP(false, "solrtest", HTTPClient(cli), Wire(WireJavabin))
+		Expect(err).To(BeNil())
+		r, err := s.Select([]string{"http://a.foo.bar"}, Query("*:*"))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		Expect(string(body)).To(ContainSubstring("wt=javabin"))
+		Expect(r.ResponseHeader.QTime).To(Equal(4))
+		Expect(r.NextCursorMark).To(Equal("AoE"))
+		Expect(r.Response.NumFound).To(BeEquivalentTo(42))
+		Expect(r.Response.Start).To(Equal(10))
+		Expect(r.Response.Docs[0]).To(Equal(map[string]interface{}{
+			"id": "1", "count": float64(3), "score": 1.1, "created": "2020-01-02T03:04:05Z", "_version_": int64(1603386011406549001),
+		}))
+	})
+
+	It("sends updates as javabin docs and deletes", func() {
+		cli := &javabinHTTPer{}
+		s, err := NewSolrHTTP(false, "solrtest", HTTPClient(cli), Wire(WireJavabin))
+		Expect(err).To(BeNil())
+		s.Update([]string{"http://a.foo.bar"}, true, map[string]interface{}{"id": "1", "tags": []string{"a"}})
+		s.Update([]string{"http://a.foo.bar"}, false, DeleteRequest{Delete: []string{"2"}})
+		Expect(cli.requests[0].URL.Path).To(Equal("/solrtest/update"))
+		Expect(cli.requests[0].Header.Get("Content-Type")).To(Equal(javabinContentType))
+
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		req, err := newJavabinDecoder(bytes.NewReader(body)).Decode()
+		Expect(err).To(BeNil())
+		entries := req.(*namedList).entries
+		Expect(entries[1].name).To(Equal("docs"))
+		Expect(entries[1].value).To(Equal([]interface{}{solrInputDocument{"id": "1", "tags": []interface{}{"a"}}}))
+
+		body, _ = ioutil.ReadAll(cli.requests[1].Body)
+		req, err = newJavabinDecoder(bytes.NewReader(body)).Decode()
+		Expect(err).To(BeNil())
+		entries = req.(*namedList).entries
+		Expect(entries[1].name).To(Equal("delById"))
+		Expect(entries[1].value).To(Equal([]interface{}{"2"}))
+	})
+
+	It("returns an error for truncated and oversized bodies", func() {
+		decode := func(b []byte) error {
+			_, err := newJavabinDecoder(bytes.NewReader(b)).Decode()
+			return err
+		}
+		body := javabinBytes(&namedList{entries: []namedEntry{{name: "tags", value: []interface{}{"a string longer than thirty one bytes to need a vint"}}}})
+		for i := 1; i < len(body); i++ {
+			Expect(decode(body[:i])).To(HaveOccurred())
+		}
+		// a string, an array, a byte array and a map claiming more than solr can write
+		huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+		Expect(decode(append([]byte{2, jbStr | 0x1f}, huge...))).To(MatchError(ContainSubstring("out of range")))
+		Expect(decode(append([]byte{2, jbArr | 0x1f}, huge...))).To(MatchError(ContainSubstring("out of range")))
+		Expect(decode(append([]byte{2, jbByteArr}, huge...))).To(MatchError(ContainSubstring("out of range")))
+		Expect(decode(append([]byte{2, jbMap}, huge...))).To(MatchError(ContainSubstring("out of range")))
+		// sizes in range but past the end of the body
+		Expect(decode([]byte{2, jbStr | 0x1f, 0xff, 0xff, 0xff, 0xff, 0x03, 'a'})).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(decode([]byte{2, jbArr | 0x1f, 0xff, 0xff, 0xff, 0xff, 0x03, 0})).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(decode(append([]byte{2}, bytes.Repeat([]byte{jbArr | 1}, 2*javabinMaxDepth)...))).To(MatchError(ContainSubstring("nested deeper")))
+	})
+})
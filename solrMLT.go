@@ -0,0 +1,168 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MoreLikeThisResponse is the response of the /mlt handler. Match is the seed doc, empty when the
+// similarity was computed from text, and Response the similar docs, decode them with DecodeDocs
+type MoreLikeThisResponse struct {
+	ResponseHeader   ResponseHeader   `json:"responseHeader"`
+	Match            Response         `json:"match"`
+	Response         Response         `json:"response"`
+	InterestingTerms InterestingTerms `json:"interestingTerms"`
+}
+
+// InterestingTerm is a term the similarity query was built from as field:term, Boost is only
+// sent with MLTInterestingTerms("details")
+type InterestingTerm struct {
+	Term  string
+	Boost float64
+}
+
+// InterestingTerms are the terms of the similarity query in order, solr sends them as a list of terms
+// or as a flat [term, boost, ...] list with details
+type InterestingTerms []InterestingTerm
+
+func (t *InterestingTerms) UnmarshalJSON(b []byte) error {
+	var list []interface{}
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		entries, err := rawNamedEntries(b)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			var boost interface{}
+			if err := json.Unmarshal(entry.value, &boost); err != nil {
+				return err
+			}
+			list = append(list, entry.name, boost)
+		}
+	} else if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	terms := make(InterestingTerms, 0, len(list))
+	for i := 0; i < len(list); i++ {
+		term, ok := list[i].(string)
+		if !ok {
+			return fmt.Errorf("[go-solr] mlt: %v is not an interesting term", list[i])
+		}
+		interesting := InterestingTerm{Term: term}
+		if i+1 < len(list) {
+			if boost, ok := list[i+1].(float64); ok {
+				interesting.Boost = boost
+				i++
+			}
+		}
+		terms = append(terms, interesting)
+	}
+	*t = terms
+	return nil
+}
+
+// MoreLikeThisID finds the docs similar to the doc id. The request is sent to a core of the shard the
+// composite id hashes to, the mlt handler does not distribute and only compares docs of that core, so docs
+// sharing a route prefix like customer!contact are compared to each other. A missing seed doc is a
+// DocNotFoundError unless MLTMatchInclude(false) left it out of the response
+func MoreLikeThisID(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, id string, opts ...func(url.Values)) (MoreLikeThisResponse, error) {
+	groups, err := groupByShard(locator, []string{id})
+	if err != nil {
+		return MoreLikeThisResponse{}, err
+	}
+	shardCores, err := getShardCores(locator)
+	if err != nil {
+		return MoreLikeThisResponse{}, err
+	}
+	var coreUris []string
+	for shard := range groups {
+		coreUris = shardCores[shard]
+	}
+	if len(coreUris) == 0 {
+		return MoreLikeThisResponse{}, fmt.Errorf("[go-solr] mlt: no core found for the shard of doc %s", id)
+	}
+	mltOpts := make([]func(url.Values), 0, len(opts)+1)
+	mltOpts = append(mltOpts, opts...)
+	mltOpts = append(mltOpts, Query(localParams(id, []string{"term", "f=" + uniqueKey})))
+	r, err := cli.MoreLikeThisCores(ctx, coreUris, mltOpts...)
+	if err != nil {
+		return r, err
+	}
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	if r.Match.NumFound == 0 && params.Get("mlt.match.include") != "false" {
+		return r, DocNotFoundError{ID: id}
+	}
+	return r, nil
+}
+
+// MLTFields sets the fields the similarity is computed on, they should store term vectors
+func MLTFields(fields ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.fl", strings.Join(fields, ","))
+	}
+}
+
+// MLTMinTF ignores the terms appearing less than minTF times in the seed doc, 2 by default
+func MLTMinTF(minTF int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.mintf", strconv.Itoa(minTF))
+	}
+}
+
+// MLTMinDF ignores the terms appearing in less than minDF docs, 5 by default
+func MLTMinDF(minDF int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.mindf", strconv.Itoa(minDF))
+	}
+}
+
+// MLTMaxDF ignores the terms appearing in more than maxDF docs
+func MLTMaxDF(maxDF int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.maxdf", strconv.Itoa(maxDF))
+	}
+}
+
+// MLTMaxQueryTerms caps the number of terms in the similarity query, 25 by default
+func MLTMaxQueryTerms(maxQueryTerms int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.maxqt", strconv.Itoa(maxQueryTerms))
+	}
+}
+
+// MLTBoost boosts the terms of the similarity query by their relevance
+func MLTBoost(boost bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.boost", strconv.FormatBool(boost))
+	}
+}
+
+// MLTQueryFields boosts the terms by field, like "title^2" "body"
+func MLTQueryFields(fields ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.qf", strings.Join(fields, " "))
+	}
+}
+
+// MLTInterestingTerms returns the terms of the similarity query, "list" for the terms only
+// and "details" for their boosts too
+func MLTInterestingTerms(mode string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.interestingTerms", mode)
+	}
+}
+
+// MLTMatchInclude returns the seed doc in Match, true by default
+func MLTMatchInclude(include bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("mlt.match.include", strconv.FormatBool(include))
+	}
+}
@@ -0,0 +1,95 @@
+package solr_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("More Like This", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	var locator *fakeLocator
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		locator = &fakeLocator{shardCores: map[string][]string{
+			"acme":    {"http://a.foo.bar/solr/core1"},
+			"initech": {"http://b.foo.bar/solr/core2"},
+		}}
+	})
+
+	It("sends the seed id to its shard and decodes the similar docs", func() {
+		cli.body = `{
+			"match":{"numFound":1,"start":0,"docs":[{"id":"initech!1","name":"peter"}]},
+			"response":{"numFound":2,"start":0,"docs":[{"id":"initech!2","name":"samir"},{"id":"initech!3","name":"michael"}]},
+			"interestingTerms":["name:peter",1.0,"title:engineer",0.5]}`
+		r, err := solr.MoreLikeThisID(context.Background(), solrHttp, locator, "initech!1",
+			solr.MLTFields("name", "title"), solr.MLTMinTF(1), solr.MLTMinDF(2), solr.MLTBoost(true),
+			solr.MLTQueryFields("name^2", "title"), solr.MLTInterestingTerms("details"), solr.Rows(2))
+		Expect(err).To(BeNil())
+		Expect(cli.requests).To(HaveLen(1))
+		Expect(cli.requests[0].URL.String()).To(Equal("http://b.foo.bar/solr/core2/mlt"))
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("q")).To(Equal("{!term f=id}initech!1"))
+		Expect(params.Get("mlt.fl")).To(Equal("name,title"))
+		Expect(params.Get("mlt.mintf")).To(Equal("1"))
+		Expect(params.Get("mlt.mindf")).To(Equal("2"))
+		Expect(params.Get("mlt.boost")).To(Equal("true"))
+		Expect(params.Get("mlt.qf")).To(Equal("name^2 title"))
+		Expect(params.Get("mlt.interestingTerms")).To(Equal("details"))
+
+		Expect(r.Match.Docs[0]["name"]).To(Equal("peter"))
+		Expect(r.Response.NumFound).To(Equal(uint32(2)))
+		Expect(r.InterestingTerms).To(Equal(solr.InterestingTerms{{Term: "name:peter", Boost: 1}, {Term: "title:engineer", Boost: 0.5}}))
+		type contact struct {
+			ID   string `solr:"id"`
+			Name string `solr:"name"`
+		}
+		var similar []contact
+		Expect(solr.DecodeDocs(r.Response.Docs, &similar)).To(BeNil())
+		Expect(similar).To(Equal([]contact{{ID: "initech!2", Name: "samir"}, {ID: "initech!3", Name: "michael"}}))
+	})
+
+	It("reports a missing seed doc", func() {
+		cli.body = `{"match":{"numFound":0,"start":0,"docs":[]},"response":{"numFound":0,"start":0,"docs":[]}}`
+		_, err := solr.MoreLikeThisID(context.Background(), solrHttp, locator, "acme!404")
+		Expect(errors.Is(err, solr.ErrNotFound)).To(BeTrue())
+		Expect(err).To(Equal(solr.DocNotFoundError{ID: "acme!404"}))
+
+		_, err = solr.MoreLikeThisID(context.Background(), solrHttp, locator, "acme!404", solr.MLTMatchInclude(false))
+		Expect(err).To(BeNil())
+	})
+
+	It("reports a shard without cores", func() {
+		_, err := solr.MoreLikeThisID(context.Background(), solrHttp, locator, "globex!1")
+		Expect(err).NotTo(BeNil())
+		Expect(cli.requests).To(BeEmpty())
+	})
+
+	It("posts raw text as the content stream", func() {
+		cli.body = `{"response":{"numFound":1,"start":0,"docs":[{"id":"acme!7"}]},"interestingTerms":["body:printer","body:jam"]}`
+		r, err := solrHttp.MoreLikeThis(context.Background(), []string{"http://a.foo.bar"}, "the printer is jammed again",
+			solr.MLTFields("body"), solr.MLTInterestingTerms("list"))
+		Expect(err).To(BeNil())
+		req := cli.requests[0]
+		Expect(req.Header.Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+		Expect(req.URL.Path).To(Equal("/solrtest/mlt"))
+		Expect(req.URL.Query().Get("mlt.fl")).To(Equal("body"))
+		Expect(req.URL.Query().Get("wt")).To(Equal("json"))
+		body, _ := ioutil.ReadAll(req.Body)
+		Expect(string(body)).To(Equal("the printer is jammed again"))
+		Expect(r.Response.Docs[0]["id"]).To(Equal("acme!7"))
+		Expect(r.InterestingTerms).To(Equal(solr.InterestingTerms{{Term: "body:printer"}, {Term: "body:jam"}}))
+	})
+})
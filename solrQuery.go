@@ -0,0 +1,171 @@
+package solr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Q is a query of the standard lucene parser built with its values escaped, pass it to Query or
+// FilterQuery with String. Qs compose with Must, Should and MustNot, Tag and LocalParams only apply to
+// the outermost Q since solr reads local params at the start of the query
+type Q string
+
+func (q Q) String() string {
+	return string(q)
+}
+
+// queryEscaper escapes the characters the lucene parser treats as syntax, whitespace included
+var queryEscaper = strings.NewReplacer(
+	`\`, `\\`, `+`, `\+`, `-`, `\-`, `!`, `\!`, `(`, `\(`, `)`, `\)`, `:`, `\:`, `^`, `\^`,
+	`[`, `\[`, `]`, `\]`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `~`, `\~`, `*`, `\*`, `?`, `\?`,
+	`|`, `\|`, `&`, `\&`, `/`, `\/`, ` `, `\ `, "\t", "\\\t", "\n", "\\\n",
+)
+
+// wildcardEscaper is queryEscaper keeping * and ? as wildcards
+var wildcardEscaper = strings.NewReplacer(
+	`\`, `\\`, `+`, `\+`, `-`, `\-`, `!`, `\!`, `(`, `\(`, `)`, `\)`, `:`, `\:`, `^`, `\^`,
+	`[`, `\[`, `]`, `\]`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `~`, `\~`,
+	`|`, `\|`, `&`, `\&`, `/`, `\/`, ` `, `\ `, "\t", "\\\t", "\n", "\\\n",
+)
+
+// phraseEscaper escapes the characters that end a quoted phrase
+var phraseEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Escape escapes the lucene syntax in s so it is read as a single term. The empty string and the
+// AND, OR and NOT operators are quoted since the parser reads them as no term or as an operator
+func Escape(s string) string {
+	if s == "" || isOperator(s) {
+		return `"` + s + `"`
+	}
+	return queryEscaper.Replace(s)
+}
+
+func isOperator(s string) bool {
+	return s == "AND" || s == "OR" || s == "NOT"
+}
+
+// Raw is a query string used as is, for syntax the builder does not cover. It must not contain user input
+func Raw(q string) Q {
+	return Q(q)
+}
+
+// MatchAll matches every doc
+func MatchAll() Q {
+	return Q("*:*")
+}
+
+// Term matches the docs whose field has the term value
+func Term(field string, value string) Q {
+	return Q(Escape(field) + ":" + Escape(value))
+}
+
+// Phrase matches the docs whose field has the words of value next to each other
+func Phrase(field string, value string) Q {
+	return Q(Escape(field) + `:"` + phraseEscaper.Replace(value) + `"`)
+}
+
+// Prefix matches the docs whose field has a term starting with prefix, an empty prefix matches
+// every doc with a term in field
+func Prefix(field string, prefix string) Q {
+	return Q(Escape(field) + ":" + queryEscaper.Replace(prefix) + "*")
+}
+
+// Wildcard matches the docs whose field has a term matching pattern, where * matches any
+// characters and ? a single one. Every other character is escaped
+func Wildcard(field string, pattern string) Q {
+	if pattern == "" || isOperator(pattern) {
+		return Term(field, pattern)
+	}
+	return Q(Escape(field) + ":" + wildcardEscaper.Replace(pattern))
+}
+
+// Range matches the docs whose field is between from and to included. A nil bound is open, strings
+// are quoted, time.Time is formatted as a solr date and other values like numbers are formatted as is
+func Range(field string, from interface{}, to interface{}) Q {
+	return Q(Escape(field) + ":[" + rangeBound(from) + " TO " + rangeBound(to) + "]")
+}
+
+// RangeExclusive is Range with from and to excluded
+func RangeExclusive(field string, from interface{}, to interface{}) Q {
+	return Q(Escape(field) + ":{" + rangeBound(from) + " TO " + rangeBound(to) + "}")
+}
+
+func rangeBound(bound interface{}) string {
+	switch bound := bound.(type) {
+	case nil:
+		return "*"
+	case string:
+		return `"` + phraseEscaper.Replace(bound) + `"`
+	case time.Time:
+		return bound.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(bound, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(bound), 'f', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(bound)
+	}
+	return `"` + phraseEscaper.Replace(fmt.Sprint(bound)) + `"`
+}
+
+// ExactTerm matches the value of field as a single term without analysis through the term parser,
+// like an id or a string field. The value is passed as a local param so it needs no escaping
+func ExactTerm(field string, value string) Q {
+	return Q("{!term f=" + localParamValue(field) + " v=" + localParamValue(value) + "}")
+}
+
+// localParamValue quotes a local param value
+func localParamValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// Must matches the docs matching every q, or every doc without qs
+func Must(qs ...Q) Q {
+	return boolQuery("+", qs)
+}
+
+// Should matches the docs matching any q, the more they match the higher they score. Without qs
+// it matches every doc like Must
+func Should(qs ...Q) Q {
+	return boolQuery("", qs)
+}
+
+// MustNot matches the docs matching none of qs
+func MustNot(qs ...Q) Q {
+	// a purely negative group matches nothing when nested, so it is subtracted from every doc
+	clauses := make([]string, 0, len(qs)+1)
+	clauses = append(clauses, MatchAll().String())
+	for _, q := range qs {
+		clauses = append(clauses, "-"+q.String())
+	}
+	return Q("(" + strings.Join(clauses, " ") + ")")
+}
+
+func boolQuery(occur string, qs []Q) Q {
+	// solr cannot parse an empty group
+	if len(qs) == 0 {
+		return MatchAll()
+	}
+	clauses := make([]string, len(qs))
+	for i, q := range qs {
+		clauses[i] = occur + q.String()
+	}
+	return Q("(" + strings.Join(clauses, " ") + ")")
+}
+
+// Boost multiplies the score of the docs matching q by boost
+func Boost(q Q, boost float64) Q {
+	return Q("(" + q.String() + ")^" + strconv.FormatFloat(boost, 'f', -1, 64))
+}
+
+// Tag tags a filter query so facets can exclude it with the ex local param
+func Tag(q Q, tags ...string) Q {
+	return LocalParams(q, "tag="+strings.Join(tags, ","))
+}
+
+// LocalParams prefixes q with local params like "cache=false" or "cost=100"
+func LocalParams(q Q, params ...string) Q {
+	return Q(localParams(q.String(), params))
+}
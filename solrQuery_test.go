@@ -0,0 +1,77 @@
+package solr_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Query builder", func() {
+	It("escapes the lucene syntax in values", func() {
+		Expect(solr.Escape(`a:b c!d+(e)`)).To(Equal(`a\:b\ c\!d\+\(e\)`))
+		Expect(solr.Term("id", "acme!1").String()).To(Equal(`id:acme\!1`))
+		Expect(solr.Term("name", `o"neil OR *:*`).String()).To(Equal(`name:o\"neil\ OR\ \*\:\*`))
+		Expect(solr.Phrase("title", `say "hi" \o/`).String()).To(Equal(`title:"say \"hi\" \\o/"`))
+		Expect(solr.Prefix("id", "acme!rando").String()).To(Equal(`id:acme\!rando*`))
+		Expect(solr.Wildcard("email", "j?hn*@a-b.com").String()).To(Equal(`email:j?hn*@a\-b.com`))
+	})
+
+	It("quotes the values the parser would not read as a term", func() {
+		Expect(solr.Term("name", "").String()).To(Equal(`name:""`))
+		Expect(solr.Term("name", "AND").String()).To(Equal(`name:"AND"`))
+		Expect(solr.Term("name", "OR").String()).To(Equal(`name:"OR"`))
+		Expect(solr.Term("name", "NOT").String()).To(Equal(`name:"NOT"`))
+		Expect(solr.Term("name", "not").String()).To(Equal(`name:not`))
+		Expect(solr.Wildcard("name", "OR").String()).To(Equal(`name:"OR"`))
+		Expect(solr.Wildcard("name", "").String()).To(Equal(`name:""`))
+		Expect(solr.Prefix("name", "OR").String()).To(Equal(`name:OR*`))
+		Expect(solr.Prefix("name", "").String()).To(Equal(`name:*`))
+	})
+
+	It("matches every doc with an empty clause list", func() {
+		Expect(solr.Must().String()).To(Equal(`*:*`))
+		Expect(solr.Should().String()).To(Equal(`*:*`))
+		Expect(solr.MustNot().String()).To(Equal(`(*:*)`))
+		Expect(solr.Must(solr.Term("type", "contact"), solr.Should()).String()).To(Equal(`(+type:contact +*:*)`))
+	})
+
+	It("builds ranges", func() {
+		Expect(solr.Range("price", 10, 99.5).String()).To(Equal(`price:[10 TO 99.5]`))
+		Expect(solr.Range("price", nil, 100).String()).To(Equal(`price:[* TO 100]`))
+		Expect(solr.RangeExclusive("name", "a", `b"] OR x`).String()).To(Equal(`name:{"a" TO "b\"] OR x"}`))
+		from := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*3600))
+		Expect(solr.Range("date", from, nil).String()).To(Equal(`date:[2020-01-02T08:04:05Z TO *]`))
+	})
+
+	It("composes boolean queries, boosts and local params", func() {
+		q := solr.Must(
+			solr.Term("type", "contact"),
+			solr.Should(solr.Boost(solr.Phrase("name", "john smith"), 2), solr.Prefix("email", "john")),
+			solr.MustNot(solr.Term("status", "deleted"), solr.ExactTerm("id", "acme!1 it's")))
+		Expect(q.String()).To(Equal(`(+type:contact +((name:"john smith")^2 email:john*) +(*:* -status:deleted -{!term f='id' v='acme!1 it\'s'}))`))
+		Expect(solr.Tag(solr.Term("inStock", "true"), "dt", "stock").String()).To(Equal(`{!tag=dt,stock}inStock:true`))
+		Expect(solr.LocalParams(solr.MatchAll(), "cache=false", "cost=100").String()).To(Equal(`{!cache=false cost=100}*:*`))
+		Expect(solr.ExactTerm("id", "acme!1").String()).To(Equal(`{!term f='id' v='acme!1'}`))
+		Expect(solr.Raw("_val_:1").String()).To(Equal("_val_:1"))
+	})
+
+	It("sends every filter query", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query(solr.MatchAll().String()),
+			solr.FilterQuery(solr.Term("last_name", "o'neil").String()),
+			solr.FilterQuery(solr.Tag(solr.Range("age", 18, nil), "age").String()))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("q")).To(Equal("*:*"))
+		Expect(params["fq"]).To(Equal([]string{`last_name:o'neil`, `{!tag=age}age:[18 TO *]`}))
+	})
+})
@@ -1,5 +1,11 @@
 package solr
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 type SolrResponse struct {
 	Status int `json:"status"`
 	QTime  int `json:"qtime"`
@@ -8,9 +14,23 @@ type SolrResponse struct {
 		Indent string `json:"indent"`
 		Wt     string `json:"wt"`
 	} `json:"params"`
-	Response       Response `json:"response"`
-	NextCursorMark string   `json:"nextCursorMark"`
-	Adds           Adds     `json:"adds"`
+	ResponseHeader ResponseHeader `json:"responseHeader"`
+	Response       Response       `json:"response"`
+	NextCursorMark string         `json:"nextCursorMark"`
+	Adds           Adds           `json:"adds"`
+	FacetCounts    FacetCounts    `json:"facet_counts"`
+	Facets         FacetBucket    `json:"facets"`
+	Highlighting   Highlighting   `json:"highlighting"`
+	Grouped        Grouped        `json:"grouped"`
+	Expanded       Expanded       `json:"expanded"`
+	Stats          Stats          `json:"stats"`
+	Spellcheck     Spellcheck     `json:"spellcheck"`
+}
+
+type ResponseHeader struct {
+	Status int                    `json:"status"`
+	QTime  int                    `json:"QTime"`
+	Params map[string]interface{} `json:"params"`
 }
 
 type Response struct {
@@ -20,8 +40,8 @@ type Response struct {
 }
 
 func GetDocIdFromDoc(m map[string]interface{}) string {
-	if v, ok := m["id"]; ok {
-		return v.(string)
+	if v, ok := m["id"].(string); ok {
+		return v
 	}
 	return ""
 }
@@ -33,12 +53,43 @@ func GetVersionFromDoc(m map[string]interface{}) int {
 			return int(v)
 		case int:
 			return v
+		case int64:
+			return int(v)
 		}
 	}
 
 	return 0
 }
 
+// exactVersion turns the json numbers of a doc decoded with UseNumber back into float64,
+// except _version_ which becomes an exact int64
+func exactVersion(doc map[string]interface{}) {
+	for k, v := range doc {
+		if n, ok := v.(json.Number); ok && k == versionField {
+			if version, err := n.Int64(); err == nil {
+				doc[k] = version
+				continue
+			}
+		}
+		doc[k] = numbersToFloat(v)
+	}
+}
+
+func numbersToFloat(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = numbersToFloat(v[i])
+		}
+	case map[string]interface{}:
+		exactVersion(v)
+	}
+	return v
+}
+
 type Adds map[string]int
 
 type UpdateResponse struct {
@@ -52,7 +103,127 @@ type UpdateResponse struct {
 		Metadata []string `json:"metadata"`
 		Msg      string   `json:"msg"`
 		Code     int      `json:"code"`
+		Trace    string   `json:"trace"`
+	}
+	Adds    DocVersions `json:"adds"`
+	Deletes DocVersions `json:"deletes"`
+}
+
+// mergeUpdateResponses combines the responses to the parts of a split batch, the rf is the lowest achieved
+func mergeUpdateResponses(a, b UpdateResponse) UpdateResponse {
+	merged := a
+	merged.Response.QTime += b.Response.QTime
+	if b.Response.RF < merged.Response.RF {
+		merged.Response.RF = b.Response.RF
+	}
+	if b.Response.MinRF > merged.Response.MinRF {
+		merged.Response.MinRF = b.Response.MinRF
 	}
+	merged.Adds = mergeDocVersions(a.Adds, b.Adds)
+	merged.Deletes = mergeDocVersions(a.Deletes, b.Deletes)
+	return merged
+}
+
+// mergeUpdateResults merges the results of updates sent to different shards, the smallest rf
+// is kept since the merged update is only as replicated as its least replicated part
+func mergeUpdateResults(a, b UpdateResult) UpdateResult {
+	merged := a
+	merged.QTime += b.QTime
+	if b.RF < merged.RF {
+		merged.RF = b.RF
+	}
+	if b.MinRF > merged.MinRF {
+		merged.MinRF = b.MinRF
+	}
+	merged.Adds = mergeDocVersions(a.Adds, b.Adds)
+	merged.Deletes = mergeDocVersions(a.Deletes, b.Deletes)
+	return merged
+}
+
+func mergeDocVersions(a, b DocVersions) DocVersions {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make(DocVersions, len(a)+len(b))
+	for id, version := range a {
+		merged[id] = version
+	}
+	for id, version := range b {
+		merged[id] = version
+	}
+	return merged
+}
+
+// UpdateResult is the outcome of a successful update, Adds and Deletes are only set when the update asks for Versions
+type UpdateResult struct {
+	QTime int
+	RF    int
+	MinRF int
+	// Adds holds the version assigned to every added doc by id
+	Adds DocVersions
+	// Deletes holds the version of the delete of every doc deleted by id
+	Deletes DocVersions
+}
+
+// DocVersions maps doc ids to versions, solr sends them as a flat [id, version, ...] list or as an object
+type DocVersions map[string]int64
+
+func (v *DocVersions) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	versions := DocVersions{}
+	var m map[string]json.Number
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		if err := dec.Decode(&m); err != nil {
+			return err
+		}
+		for id, n := range m {
+			version, err := n.Int64()
+			if err != nil {
+				return err
+			}
+			versions[id] = version
+		}
+		*v = versions
+		return nil
+	}
+	var list []interface{}
+	if err := dec.Decode(&list); err != nil {
+		return err
+	}
+	if len(list)%2 != 0 {
+		return fmt.Errorf("[go-solr] versions: odd number of elements in %s", b)
+	}
+	for i := 0; i < len(list); i += 2 {
+		n, ok := list[i+1].(json.Number)
+		if !ok {
+			return fmt.Errorf("[go-solr] versions: %v is not a version", list[i+1])
+		}
+		version, err := n.Int64()
+		if err != nil {
+			return err
+		}
+		versions[fmt.Sprint(list[i])] = version
+	}
+	*v = versions
+	return nil
+}
+
+// CommitResponse is the response of a Commit or Optimize
+type CommitResponse struct {
+	Status int
+	QTime  int
+}
+
+// ExportResponse describes the stream read from the export handler of one shard
+type ExportResponse struct {
+	NumFound uint32
+	Exported int
+	// EOF is true when the stream was read to its end marker
+	EOF bool
 }
 
 type DeleteRequest struct {
@@ -0,0 +1,82 @@
+package solr_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Oversized Batches", func() {
+	ok := func(id string, version int) string {
+		return `{"responseHeader":{"status":0,"QTime":1,"rf":1,"min_rf":1},"adds":["` + id + `",` + strconv.Itoa(version) + `]}`
+	}
+	docs := []map[string]interface{}{{"id": "a"}, {"id": "b"}, {"id": "c"}, {"id": "d"}}
+
+	It("bisects a batch solr rejects and reports the docs that never fit", func() {
+		cli := &fakeHTTPer{
+			statuses: []int{413, 200, 413, 413, 200},
+			bodies:   []string{"too large", ok("a", 1), "too large", "too large", ok("d", 4)},
+		}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		retrier := solr.NewSolrHttpRetrier(solrHttp, 3, time.Millisecond)
+		r, err := retrier.UpdateContext(context.Background(), []string{"http://leader1.foo.bar"}, false, docs)
+		tooLarge, isTooLarge := err.(solr.PayloadTooLargeError)
+		Expect(isTooLarge).To(BeTrue())
+		Expect(tooLarge.Indexes).To(Equal([]int{2}))
+		Expect(tooLarge.Docs).To(Equal([]interface{}{docs[2]}))
+		Expect(errors.Is(err, solr.ErrPayloadTooLarge)).To(BeTrue())
+		Expect(r.Adds).To(Equal(solr.DocVersions{"a": 1, "d": 4}))
+		Expect(r.QTime).To(Equal(2))
+		Expect(cli.requests).To(HaveLen(5))
+	})
+
+	It("checks min_rf on the docs of a split batch that were indexed", func() {
+		cli := &fakeHTTPer{
+			statuses: []int{413, 200, 413},
+			bodies:   []string{"too large", `{"responseHeader":{"status":0,"rf":1,"min_rf":2},"adds":["a",1]}`, "too large"},
+		}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.MinRF(2))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.UpdateContext(context.Background(), []string{"http://leader1.foo.bar"}, false, docs[:2])
+		Expect(errors.Is(err, solr.ErrPayloadTooLarge)).To(BeTrue())
+		Expect(errors.Is(err, solr.ErrMinRF)).To(BeTrue())
+		tooLarge := err.(solr.PayloadTooLargeError)
+		Expect(tooLarge.Indexes).To(Equal([]int{1}))
+		Expect(tooLarge.MinRFError.RF).To(Equal(1))
+		Expect(tooLarge.MinRFError.MinRF).To(Equal(2))
+		Expect(r.RF).To(Equal(1))
+	})
+
+	It("returns the docs written before a part fails", func() {
+		cli := &fakeHTTPer{
+			statuses: []int{413, 200, 500},
+			bodies:   []string{"too large", ok("a", 1), `{"error":{"msg":"boom","code":500}}`},
+		}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.UpdateContext(context.Background(), []string{"http://leader1.foo.bar"}, false, docs[:2])
+		Expect(err).NotTo(BeNil())
+		Expect(errors.Is(err, solr.ErrPayloadTooLarge)).To(BeFalse())
+		Expect(r.Adds).To(Equal(solr.DocVersions{"a": 1}))
+		Expect(r.RF).To(Equal(1))
+	})
+
+	It("splits a batch over the configured limit before sending it", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: ok("a", 1)}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.MaxUpdateBytes(20))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.UpdateContext(context.Background(), []string{"http://leader1.foo.bar"}, false, docs[:2])
+		Expect(err).To(BeNil())
+		Expect(cli.requests).To(HaveLen(2))
+		body, _ := ioutil.ReadAll(cli.requests[1].Body)
+		Expect(string(body)).To(MatchJSON(`[{"id":"b"}]`))
+	})
+})
@@ -0,0 +1,96 @@
+package solr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Stats is the stats section of a response, keyed by stats.field or its key local param
+type Stats struct {
+	Fields map[string]FieldStats `json:"stats_fields"`
+}
+
+// FieldStats are the stats of one field. Min, Max and Mean are float64 for numeric fields and strings
+// for date and string fields, the stats solr was not asked for through local params are zero.
+// Facets are the stats of every value of the stats.facet fields, by field and value
+type FieldStats struct {
+	Min            interface{}                      `json:"min"`
+	Max            interface{}                      `json:"max"`
+	Count          int64                            `json:"count"`
+	Missing        int64                            `json:"missing"`
+	Sum            float64                          `json:"sum"`
+	SumOfSquares   float64                          `json:"sumOfSquares"`
+	Mean           interface{}                      `json:"mean"`
+	Stddev         float64                          `json:"stddev"`
+	Percentiles    StatsPercentiles                 `json:"percentiles"`
+	Cardinality    int64                            `json:"cardinality"`
+	CountDistinct  int64                            `json:"countDistinct"`
+	DistinctValues []interface{}                    `json:"distinctValues"`
+	Facets         map[string]map[string]FieldStats `json:"facets"`
+}
+
+// StatsPercentiles are the values of a field by percentile, solr sends them as a flat
+// [percentile, value, ...] list with the percentiles as strings
+type StatsPercentiles map[float64]float64
+
+func (s *StatsPercentiles) UnmarshalJSON(b []byte) error {
+	var list []interface{}
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return err
+		}
+		for k, v := range m {
+			list = append(list, k, v)
+		}
+	} else if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	if len(list)%2 != 0 {
+		return fmt.Errorf("[go-solr] stats: odd number of elements in percentiles")
+	}
+	percentiles := StatsPercentiles{}
+	for i := 0; i < len(list); i += 2 {
+		percent, err := strconv.ParseFloat(fmt.Sprint(list[i]), 64)
+		if err != nil {
+			return fmt.Errorf("[go-solr] stats: %v is not a percentile", list[i])
+		}
+		value, err := toFloat64(list[i+1])
+		if err != nil {
+			return err
+		}
+		percentiles[percent] = value
+	}
+	*s = percentiles
+	return nil
+}
+
+// StatsField computes the stats of field. localParams choose the stats, like PercentilesParam(50, 99) or
+// "cardinality=true", tag the stats for a pivot facet with "tag=t1" or exclude filters with "ex=dt"
+func StatsField(field string, localParam ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("stats", "true")
+		p.Add("stats.field", localParams(field, localParam))
+	}
+}
+
+// StatsFacet breaks the stats of every stats.field down by the values of field.
+// To break them down by pivot facets tag the stats field and pass "stats=tag" to FacetPivot
+func StatsFacet(field string) func(url.Values) {
+	return func(p url.Values) {
+		p.Add("stats.facet", field)
+	}
+}
+
+// PercentilesParam is the local param of StatsField asking for the percentiles
+func PercentilesParam(percents ...float64) string {
+	formatted := make([]string, len(percents))
+	for i, percent := range percents {
+		formatted[i] = strconv.FormatFloat(percent, 'f', -1, 64)
+	}
+	return "percentiles='" + strings.Join(formatted, ",") + "'"
+}
@@ -0,0 +1,63 @@
+package solr_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Stats", func() {
+	It("builds the stats params", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"),
+			solr.StatsField("price", "tag=t1", "ex=dt", solr.PercentilesParam(50, 99.9), "cardinality=true"),
+			solr.StatsField("date"), solr.StatsFacet("inStock"),
+			solr.FacetPivot([]string{"cat"}, "stats=t1"))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("stats")).To(Equal("true"))
+		Expect(params["stats.field"]).To(Equal([]string{"{!tag=t1 ex=dt percentiles='50,99.9' cardinality=true}price", "date"}))
+		Expect(params.Get("stats.facet")).To(Equal("inStock"))
+		Expect(params.Get("facet.pivot")).To(Equal("{!stats=t1}cat"))
+	})
+
+	It("decodes field stats, facets and pivot stats", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"stats":{"stats_fields":{
+				"price":{"min":0.5,"max":100.0,"count":10,"missing":1,"sum":300.5,"sumOfSquares":12000.0,"mean":30.05,"stddev":12.5,
+					"percentiles":["50.0",25.0,"99.9",99.0],"cardinality":7,
+					"facets":{"inStock":{"true":{"min":1.0,"max":100.0,"count":8},"false":{"min":0.5,"max":3.0,"count":2}}}},
+				"date":{"min":"2020-01-01T00:00:00Z","max":"2021-01-01T00:00:00Z","count":10,"mean":"2020-07-01T00:00:00Z"}}},
+			"facet_counts":{"facet_pivot":{"cat":[{"field":"cat","value":"memory","count":3,
+				"stats":{"stats_fields":{"price":{"min":1.0,"max":2.0,"count":3}}}}]}}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("*:*"), solr.StatsField("price"))
+		Expect(err).To(BeNil())
+		price := r.Stats.Fields["price"]
+		Expect(price.Min).To(Equal(0.5))
+		Expect(price.Count).To(Equal(int64(10)))
+		Expect(price.Missing).To(Equal(int64(1)))
+		Expect(price.Mean).To(Equal(30.05))
+		Expect(price.Stddev).To(Equal(12.5))
+		Expect(price.Percentiles).To(Equal(solr.StatsPercentiles{50: 25, 99.9: 99}))
+		Expect(price.Cardinality).To(Equal(int64(7)))
+		Expect(price.Facets["inStock"]["false"].Max).To(Equal(3.0))
+		Expect(price.Facets["inStock"]["true"].Count).To(Equal(int64(8)))
+
+		date := r.Stats.Fields["date"]
+		Expect(date.Min).To(Equal("2020-01-01T00:00:00Z"))
+		Expect(date.Mean).To(Equal("2020-07-01T00:00:00Z"))
+
+		pivot := r.FacetCounts.Pivots["cat"][0]
+		Expect(pivot.Stats.Fields["price"].Max).To(Equal(2.0))
+	})
+})
@@ -0,0 +1,123 @@
+package solr
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StreamHeader is the part of a select response solr writes before the docs, it is handed to the
+// SelectStream callback with every doc
+type StreamHeader struct {
+	ResponseHeader ResponseHeader
+	NumFound       uint32
+	Start          int
+}
+
+// decodeSelectStream tokenizes a select response, next is called with the decoder positioned on each doc
+// of response.docs and must consume it, every other section is decoded into sr. The sections before the
+// response, like responseHeader, and numFound and start are already in sr when next is first called
+func decodeSelectStream(dec *json.Decoder, sr *SolrResponse, next func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	rest := make(map[string]json.RawMessage)
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return err
+		}
+		if key == "response" {
+			if err := decodeSections(rest, sr); err != nil {
+				return err
+			}
+			rest = make(map[string]json.RawMessage)
+			if err := decodeResponseStream(dec, &sr.Response, next); err != nil {
+				return err
+			}
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		rest[key] = raw
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	return decodeSections(rest, sr)
+}
+
+// decodeSections decodes the raw sections of a response into sr, keeping the sections already in sr
+func decodeSections(sections map[string]json.RawMessage, sr *SolrResponse) error {
+	if len(sections) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, sr)
+}
+
+func decodeResponseStream(dec *json.Decoder, r *Response, next func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "numFound":
+			err = dec.Decode(&r.NumFound)
+		case "start":
+			err = dec.Decode(&r.Start)
+		case "docs":
+			err = decodeDocsStream(dec, next)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func decodeDocsStream(dec *json.Decoder, next func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := next(dec); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func nextKey(dec *json.Decoder) (string, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("[go-solr] stream: expected an object key, got %v", t)
+	}
+	return key, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("[go-solr] stream: expected %v, got %v", delim, t)
+	}
+	return nil
+}
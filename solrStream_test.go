@@ -0,0 +1,66 @@
+package solr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Select Stream", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+	})
+
+	It("hands docs to the callback one at a time", func() {
+		cli.body = `{"responseHeader":{"status":0,"QTime":3,"params":{"q":"*:*"}},"response":{"numFound":12,"start":0,"maxScore":1.0,"docs":[{"id":"1"},{"id":"2"}]},"nextCursorMark":"AoE2"}`
+		var ids []string
+		r, err := solrHttp.SelectStream(context.Background(), []string{"http://a.foo.bar"}, func(header solr.StreamHeader, doc map[string]interface{}) error {
+			Expect(header.NumFound).To(BeEquivalentTo(12))
+			Expect(header.ResponseHeader.QTime).To(Equal(3))
+			ids = append(ids, solr.GetDocIdFromDoc(doc))
+			return nil
+		}, solr.Query("*:*"))
+		Expect(err).To(BeNil())
+		Expect(ids).To(Equal([]string{"1", "2"}))
+		Expect(r.Response.NumFound).To(BeEquivalentTo(12))
+		Expect(r.Response.Docs).To(BeEmpty())
+		Expect(r.ResponseHeader.QTime).To(Equal(3))
+		Expect(r.ResponseHeader.Params["q"]).To(Equal("*:*"))
+		Expect(r.NextCursorMark).To(Equal("AoE2"))
+	})
+
+	It("stops on a callback error", func() {
+		cli.body = `{"response":{"numFound":2,"docs":[{"id":"1"},{"id":"2"}]}}`
+		stop := errors.New("stop")
+		calls := 0
+		_, err := solrHttp.SelectStream(context.Background(), []string{"http://a.foo.bar"}, func(header solr.StreamHeader, doc map[string]interface{}) error {
+			calls++
+			return stop
+		})
+		Expect(err).To(Equal(stop))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("does not retry a stream that already delivered docs", func() {
+		cli.body = `{"response":{"numFound":2,"docs":[{"id":"1"},{"id":`
+		retrier := solr.NewSolrHttpRetrier(solrHttp, 3, time.Millisecond)
+		calls := 0
+		_, err := retrier.SelectStream(context.Background(), []string{"http://a.foo.bar"}, func(header solr.StreamHeader, doc map[string]interface{}) error {
+			calls++
+			return nil
+		})
+		Expect(err).To(Not(BeNil()))
+		Expect(calls).To(Equal(1))
+		Expect(cli.requests).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,277 @@
+package solr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SuggestResponse is the response of the /suggest handler, the suggestions by dictionary by query
+type SuggestResponse struct {
+	ResponseHeader ResponseHeader                      `json:"responseHeader"`
+	Suggest        map[string]map[string]SuggestResult `json:"suggest"`
+}
+
+// SuggestResult are the suggestions of one dictionary for one query
+type SuggestResult struct {
+	NumFound    int          `json:"numFound"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion is a suggested term, Payload is empty unless the dictionary has a payload field
+type Suggestion struct {
+	Term    string `json:"term"`
+	Weight  int64  `json:"weight"`
+	Payload string `json:"payload"`
+}
+
+// Suggestions returns the suggestions of dictionary for q
+func (r SuggestResponse) Suggestions(dictionary string, q string) []Suggestion {
+	return r.Suggest[dictionary][q].Suggestions
+}
+
+// SuggestDictionary picks the dictionaries of the suggest component, every one is asked when none is picked
+func SuggestDictionary(dictionaries ...string) func(url.Values) {
+	return func(p url.Values) {
+		for _, dictionary := range dictionaries {
+			p.Add("suggest.dictionary", dictionary)
+		}
+	}
+}
+
+// SuggestQuery sets the text to suggest for
+func SuggestQuery(q string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("suggest.q", q)
+	}
+}
+
+// SuggestContextFilter restricts the suggestions to the ones whose context field matches cfq
+func SuggestContextFilter(cfq string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("suggest.cfq", cfq)
+	}
+}
+
+// SuggestCount sets the max number of suggestions per dictionary
+func SuggestCount(count int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("suggest.count", strconv.Itoa(count))
+	}
+}
+
+// SuggestBuild rebuilds the dictionaries from the index before suggesting, it is expensive on large indexes
+func SuggestBuild(build bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("suggest.build", strconv.FormatBool(build))
+	}
+}
+
+// SuggestReload reloads the dictionaries from their storage before suggesting
+func SuggestReload(reload bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("suggest.reload", strconv.FormatBool(reload))
+	}
+}
+
+// Spellcheck is the spellcheck section of a response
+type Spellcheck struct {
+	Suggestions      SpellSuggestions `json:"suggestions"`
+	CorrectlySpelled bool             `json:"correctlySpelled"`
+	Collations       Collations       `json:"collations"`
+}
+
+// SpellSuggestion are the corrections of one misspelled token of the query. Offsets and
+// OrigFreq are only sent with spellcheck.extendedResults
+type SpellSuggestion struct {
+	Token       string            `json:"-"`
+	NumFound    int               `json:"numFound"`
+	StartOffset int               `json:"startOffset"`
+	EndOffset   int               `json:"endOffset"`
+	OrigFreq    int               `json:"origFreq"`
+	Suggestion  []SpellCorrection `json:"suggestion"`
+}
+
+// SpellCorrection is a corrected word, Freq is only sent with spellcheck.extendedResults
+type SpellCorrection struct {
+	Word string `json:"word"`
+	Freq int    `json:"freq"`
+}
+
+func (c *SpellCorrection) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '"' {
+		*c = SpellCorrection{}
+		return json.Unmarshal(b, &c.Word)
+	}
+	type correction SpellCorrection
+	return json.Unmarshal(b, (*correction)(c))
+}
+
+// SpellSuggestions are the misspelled tokens in query order, solr sends them as a flat [token, suggestion, ...] list
+type SpellSuggestions []SpellSuggestion
+
+func (s *SpellSuggestions) UnmarshalJSON(b []byte) error {
+	entries, err := rawNamedEntries(b)
+	if err != nil {
+		return err
+	}
+	suggestions := make(SpellSuggestions, 0, len(entries))
+	for _, entry := range entries {
+		// solr before 5 mixed correctlySpelled and collation into the suggestions
+		if raw := bytes.TrimSpace(entry.value); len(raw) == 0 || raw[0] != '{' {
+			continue
+		}
+		suggestion := SpellSuggestion{Token: entry.name}
+		if err := json.Unmarshal(entry.value, &suggestion); err != nil {
+			return err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	*s = suggestions
+	return nil
+}
+
+// Collation is a corrected query, Hits and Corrections are only sent with spellcheck.collateExtendedResults.
+// Corrections are the corrected words by misspelled token
+type Collation struct {
+	Query       string
+	Hits        int
+	Corrections map[string]string
+}
+
+// Collations are the corrected queries, best first
+type Collations []Collation
+
+func (c *Collations) UnmarshalJSON(b []byte) error {
+	entries, err := rawNamedEntries(b)
+	if err != nil {
+		return err
+	}
+	collations := make(Collations, 0, len(entries))
+	for _, entry := range entries {
+		var collation Collation
+		if raw := bytes.TrimSpace(entry.value); len(raw) > 0 && raw[0] == '"' {
+			if err := json.Unmarshal(raw, &collation.Query); err != nil {
+				return err
+			}
+			collations = append(collations, collation)
+			continue
+		}
+		var extended struct {
+			CollationQuery             string          `json:"collationQuery"`
+			Hits                       int             `json:"hits"`
+			MisspellingsAndCorrections json.RawMessage `json:"misspellingsAndCorrections"`
+		}
+		if err := json.Unmarshal(entry.value, &extended); err != nil {
+			return err
+		}
+		collation.Query, collation.Hits = extended.CollationQuery, extended.Hits
+		if len(extended.MisspellingsAndCorrections) > 0 {
+			corrections, err := rawNamedEntries(extended.MisspellingsAndCorrections)
+			if err != nil {
+				return err
+			}
+			collation.Corrections = make(map[string]string, len(corrections))
+			for _, correction := range corrections {
+				var word string
+				if err := json.Unmarshal(correction.value, &word); err != nil {
+					return err
+				}
+				collation.Corrections[correction.name] = word
+			}
+		}
+		collations = append(collations, collation)
+	}
+	*c = collations
+	return nil
+}
+
+type rawNamedEntry struct {
+	name  string
+	value json.RawMessage
+}
+
+// rawNamedEntries reads a solr named list sent as a flat [name, value, ...] list, or as an object
+// with json.nl=map, in the order solr sent it
+func rawNamedEntries(b []byte) ([]rawNamedEntry, error) {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+		var entries []rawNamedEntry
+		for dec.More() {
+			name, err := nextKey(dec)
+			if err != nil {
+				return nil, err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			entries = append(entries, rawNamedEntry{name: name, value: value})
+		}
+		return entries, expectDelim(dec, '}')
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
+	if len(list)%2 != 0 {
+		return nil, fmt.Errorf("[go-solr] odd number of elements in named list")
+	}
+	entries := make([]rawNamedEntry, 0, len(list)/2)
+	for i := 0; i < len(list); i += 2 {
+		var name string
+		if err := json.Unmarshal(list[i], &name); err != nil {
+			return nil, err
+		}
+		entries = append(entries, rawNamedEntry{name: name, value: list[i+1]})
+	}
+	return entries, nil
+}
+
+// SpellcheckQuery turns on the spellcheck component, q is checked instead of the query when it is not empty
+func SpellcheckQuery(q string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("spellcheck", "true")
+		if q != "" {
+			p.Set("spellcheck.q", q)
+		}
+	}
+}
+
+// SpellcheckDictionary picks the dictionaries of the spellcheck component
+func SpellcheckDictionary(dictionaries ...string) func(url.Values) {
+	return func(p url.Values) {
+		for _, dictionary := range dictionaries {
+			p.Add("spellcheck.dictionary", dictionary)
+		}
+	}
+}
+
+// SpellcheckCount sets the max number of corrections per token
+func SpellcheckCount(count int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("spellcheck.count", strconv.Itoa(count))
+	}
+}
+
+// SpellcheckExtendedResults adds the frequencies and offsets to the suggestions
+func SpellcheckExtendedResults(extended bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("spellcheck.extendedResults", strconv.FormatBool(extended))
+	}
+}
+
+// SpellcheckCollate asks for up to maxCollations corrected queries, only the ones
+// returning hits are kept. collateExtendedResults adds their hits and corrections
+func SpellcheckCollate(maxCollations int, collateExtendedResults bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("spellcheck.collate", "true")
+		p.Set("spellcheck.maxCollations", strconv.Itoa(maxCollations))
+		p.Set("spellcheck.collateExtendedResults", strconv.FormatBool(collateExtendedResults))
+	}
+}
@@ -0,0 +1,109 @@
+package solr_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Suggest", func() {
+	It("asks the suggest handler and decodes the suggestions", func() {
+		cli := &fakeHTTPer{
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			bodies: []string{"", `{
+				"responseHeader":{"status":0,"QTime":1},
+				"suggest":{"titles":{"elec":{"numFound":2,"suggestions":[
+					{"term":"electronics","weight":10,"payload":""},
+					{"term":"electric","weight":3,"payload":"p"}]}}}}`},
+		}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli), solr.User("user"), solr.Password("pass"))
+		Expect(err).To(BeNil())
+		retrier := solr.NewSolrHttpRetrier(solrHttp, 3, time.Millisecond)
+		r, err := retrier.Suggest(context.Background(), []string{"http://a.foo.bar"},
+			solr.SuggestDictionary("titles"), solr.SuggestQuery("elec"), solr.SuggestContextFilter("tenant:1"),
+			solr.SuggestCount(5), solr.SuggestBuild(true))
+		Expect(err).To(BeNil())
+		Expect(cli.requests).To(HaveLen(2))
+		req := cli.requests[1]
+		Expect(req.URL.String()).To(Equal("http://a.foo.bar/solrtest/suggest"))
+		Expect(req.Header.Get("Authorization")).NotTo(BeEmpty())
+		body, _ := ioutil.ReadAll(req.Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("suggest")).To(Equal("true"))
+		Expect(params.Get("suggest.dictionary")).To(Equal("titles"))
+		Expect(params.Get("suggest.q")).To(Equal("elec"))
+		Expect(params.Get("suggest.cfq")).To(Equal("tenant:1"))
+		Expect(params.Get("suggest.count")).To(Equal("5"))
+		Expect(params.Get("suggest.build")).To(Equal("true"))
+
+		suggestions := r.Suggestions("titles", "elec")
+		Expect(suggestions).To(Equal([]solr.Suggestion{{Term: "electronics", Weight: 10}, {Term: "electric", Weight: 3, Payload: "p"}}))
+		Expect(r.Suggest["titles"]["elec"].NumFound).To(Equal(2))
+	})
+})
+
+var _ = Describe("Spellcheck", func() {
+	It("builds the spellcheck params", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		_, err = solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("title:hell"),
+			solr.SpellcheckQuery("hell ultrashar"), solr.SpellcheckDictionary("default", "wordbreak"),
+			solr.SpellcheckCount(3), solr.SpellcheckExtendedResults(true), solr.SpellcheckCollate(2, true))
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("spellcheck")).To(Equal("true"))
+		Expect(params.Get("spellcheck.q")).To(Equal("hell ultrashar"))
+		Expect(params["spellcheck.dictionary"]).To(Equal([]string{"default", "wordbreak"}))
+		Expect(params.Get("spellcheck.count")).To(Equal("3"))
+		Expect(params.Get("spellcheck.extendedResults")).To(Equal("true"))
+		Expect(params.Get("spellcheck.collate")).To(Equal("true"))
+		Expect(params.Get("spellcheck.maxCollations")).To(Equal("2"))
+		Expect(params.Get("spellcheck.collateExtendedResults")).To(Equal("true"))
+	})
+
+	It("decodes the extended suggestions and collations", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"spellcheck":{
+				"suggestions":[
+					"hell",{"numFound":1,"startOffset":0,"endOffset":4,"origFreq":0,"suggestion":[{"word":"dell","freq":2}]},
+					"ultrashar",{"numFound":1,"startOffset":5,"endOffset":14,"origFreq":0,"suggestion":[{"word":"ultrasharp","freq":1}]}],
+				"correctlySpelled":false,
+				"collations":["collation",{"collationQuery":"dell ultrasharp","hits":1,
+					"misspellingsAndCorrections":["hell","dell","ultrashar","ultrasharp"]}]}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("hell ultrashar"), solr.SpellcheckQuery(""))
+		Expect(err).To(BeNil())
+		spellcheck := r.Spellcheck
+		Expect(spellcheck.CorrectlySpelled).To(BeFalse())
+		Expect(spellcheck.Suggestions).To(HaveLen(2))
+		Expect(spellcheck.Suggestions[0].Token).To(Equal("hell"))
+		Expect(spellcheck.Suggestions[0].Suggestion).To(Equal([]solr.SpellCorrection{{Word: "dell", Freq: 2}}))
+		Expect(spellcheck.Suggestions[1].StartOffset).To(Equal(5))
+		Expect(spellcheck.Collations).To(Equal(solr.Collations{{Query: "dell ultrasharp", Hits: 1,
+			Corrections: map[string]string{"hell": "dell", "ultrashar": "ultrasharp"}}}))
+	})
+
+	It("decodes the plain suggestions and collations", func() {
+		cli := &fakeHTTPer{status: http.StatusOK, body: `{
+			"spellcheck":{
+				"suggestions":["hell",{"numFound":2,"suggestion":["dell","bell"]}],
+				"collations":["collation","dell"]}}`}
+		solrHttp, err := solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		r, err := solrHttp.Select([]string{"http://a.foo.bar"}, solr.Query("hell"), solr.SpellcheckQuery(""))
+		Expect(err).To(BeNil())
+		Expect(r.Spellcheck.Suggestions[0].Suggestion).To(Equal([]solr.SpellCorrection{{Word: "dell"}, {Word: "bell"}}))
+		Expect(r.Spellcheck.Collations).To(Equal(solr.Collations{{Query: "dell"}}))
+	})
+})
@@ -0,0 +1,157 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TermsResponse is the response of the /terms handler, the terms of every terms.fl field.
+// IndexStats is only sent with TermsStats
+type TermsResponse struct {
+	ResponseHeader ResponseHeader       `json:"responseHeader"`
+	Terms          map[string]TermFreqs `json:"terms"`
+	IndexStats     struct {
+		NumDocs int64 `json:"numDocs"`
+	} `json:"indexstats"`
+}
+
+// TermFreq is a term of a field and the number of docs it appears in, TotalTermFreq
+// is the number of times it appears in the field and is only sent with TermsTotalTermFreq
+type TermFreq struct {
+	Term          string
+	Count         int64
+	TotalTermFreq int64
+}
+
+// TermFreqs are the terms of a field in the order of terms.sort, solr sends them as a flat [term, count, ...]
+// list, or [term, {df, ttf}, ...] with terms.ttf
+type TermFreqs []TermFreq
+
+func (t *TermFreqs) UnmarshalJSON(b []byte) error {
+	entries, err := rawNamedEntries(b)
+	if err != nil {
+		return err
+	}
+	freqs := make(TermFreqs, 0, len(entries))
+	for _, entry := range entries {
+		freq := TermFreq{Term: entry.name}
+		if raw := bytes.TrimSpace(entry.value); len(raw) > 0 && raw[0] == '{' {
+			var stats struct {
+				DF  int64 `json:"df"`
+				TTF int64 `json:"ttf"`
+			}
+			if err := json.Unmarshal(raw, &stats); err != nil {
+				return err
+			}
+			freq.Count, freq.TotalTermFreq = stats.DF, stats.TTF
+		} else if err := json.Unmarshal(raw, &freq.Count); err != nil {
+			return fmt.Errorf("[go-solr] terms: %s is not a term count", raw)
+		}
+		freqs = append(freqs, freq)
+	}
+	*t = freqs
+	return nil
+}
+
+// TermsCollection runs Terms across every shard found by the locator, the /terms handler only reads the
+// core that answers otherwise. The shards param lists the replicas of every shard so solr merges the counts
+func TermsCollection(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, opts ...func(url.Values)) (TermsResponse, error) {
+	shardCores, err := getShardCores(locator)
+	if err != nil {
+		return TermsResponse{}, err
+	}
+	nodeUris, err := locator.GetReplicaUris()
+	if err != nil {
+		return TermsResponse{}, err
+	}
+	shardNames := make([]string, 0, len(shardCores))
+	for shard := range shardCores {
+		shardNames = append(shardNames, shard)
+	}
+	sort.Strings(shardNames)
+	shards := make([]string, len(shardNames))
+	for i, shard := range shardNames {
+		shards[i] = strings.Join(shardCores[shard], "|")
+	}
+	termsOpts := make([]func(url.Values), 0, len(opts)+1)
+	termsOpts = append(termsOpts, opts...)
+	termsOpts = append(termsOpts, func(p url.Values) {
+		p["distrib"] = []string{"true"}
+		p["shards"] = []string{strings.Join(shards, ",")}
+	})
+	return cli.Terms(ctx, nodeUris, termsOpts...)
+}
+
+// TermsFields sets the fields to list the terms of
+func TermsFields(fields ...string) func(url.Values) {
+	return func(p url.Values) {
+		for _, field := range fields {
+			p.Add("terms.fl", field)
+		}
+	}
+}
+
+// TermsPrefix keeps the terms starting with prefix
+func TermsPrefix(prefix string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.prefix", prefix)
+	}
+}
+
+// TermsRegex keeps the terms matching regex, flags like "case_insensitive" change how it matches
+func TermsRegex(regex string, flags ...string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.regex", regex)
+		for _, flag := range flags {
+			p.Add("terms.regex.flag", flag)
+		}
+	}
+}
+
+// TermsLimit sets the max number of terms per field, 10 by default and -1 for every term
+func TermsLimit(limit int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.limit", strconv.Itoa(limit))
+	}
+}
+
+// TermsMinCount drops the terms appearing in less than minCount docs
+func TermsMinCount(minCount int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.mincount", strconv.Itoa(minCount))
+	}
+}
+
+// TermsMaxCount drops the terms appearing in more than maxCount docs
+func TermsMaxCount(maxCount int) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.maxcount", strconv.Itoa(maxCount))
+	}
+}
+
+// TermsSort sorts the terms by "count", the default, or by "index" order
+func TermsSort(sort string) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.sort", sort)
+	}
+}
+
+// TermsStats adds the number of docs of the index to IndexStats
+func TermsStats(stats bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.stats", strconv.FormatBool(stats))
+	}
+}
+
+// TermsTotalTermFreq adds the total term frequency of every term
+func TermsTotalTermFreq(ttf bool) func(url.Values) {
+	return func(p url.Values) {
+		p.Set("terms.ttf", strconv.FormatBool(ttf))
+	}
+}
@@ -0,0 +1,78 @@
+package solr_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Terms", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+	})
+
+	It("lists the terms of the fields in order", func() {
+		cli.body = `{"responseHeader":{"status":0,"QTime":1},
+			"terms":{"title":["solr",12,"search",5],"tags":["go",3]},
+			"indexstats":{"numDocs":100}}`
+		r, err := solrHttp.Terms(context.Background(), []string{"http://a.foo.bar"},
+			solr.TermsFields("title", "tags"), solr.TermsPrefix("s"), solr.TermsRegex("s.*", "case_insensitive"),
+			solr.TermsLimit(20), solr.TermsMinCount(2), solr.TermsMaxCount(50), solr.TermsSort("count"), solr.TermsStats(true))
+		Expect(err).To(BeNil())
+		Expect(cli.requests[0].URL.String()).To(Equal("http://a.foo.bar/solrtest/terms"))
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("terms")).To(Equal("true"))
+		Expect(params["terms.fl"]).To(Equal([]string{"title", "tags"}))
+		Expect(params.Get("terms.prefix")).To(Equal("s"))
+		Expect(params.Get("terms.regex")).To(Equal("s.*"))
+		Expect(params.Get("terms.regex.flag")).To(Equal("case_insensitive"))
+		Expect(params.Get("terms.limit")).To(Equal("20"))
+		Expect(params.Get("terms.mincount")).To(Equal("2"))
+		Expect(params.Get("terms.maxcount")).To(Equal("50"))
+		Expect(params.Get("terms.stats")).To(Equal("true"))
+		Expect(params.Get("distrib")).To(BeEmpty())
+
+		Expect(r.Terms["title"]).To(Equal(solr.TermFreqs{{Term: "solr", Count: 12}, {Term: "search", Count: 5}}))
+		Expect(r.Terms["tags"]).To(Equal(solr.TermFreqs{{Term: "go", Count: 3}}))
+		Expect(r.IndexStats.NumDocs).To(Equal(int64(100)))
+	})
+
+	It("decodes the total term frequencies", func() {
+		cli.body = `{"terms":{"title":["solr",{"df":12,"ttf":40}]}}`
+		r, err := solrHttp.Terms(context.Background(), []string{"http://a.foo.bar"}, solr.TermsFields("title"), solr.TermsTotalTermFreq(true))
+		Expect(err).To(BeNil())
+		Expect(r.Terms["title"]).To(Equal(solr.TermFreqs{{Term: "solr", Count: 12, TotalTermFreq: 40}}))
+	})
+
+	It("distributes across the shards of the locator", func() {
+		cli.body = `{"terms":{"title":["solr",30]}}`
+		locator := &fakeLocator{
+			shardCores: map[string][]string{
+				"shard1": {"http://a.foo.bar/solr/core1", "http://b.foo.bar/solr/core1r"},
+				"shard2": {"http://b.foo.bar/solr/core2"},
+			},
+			replicaUris: []string{"http://a.foo.bar/solr"},
+		}
+		r, err := solr.TermsCollection(context.Background(), solrHttp, locator, solr.TermsFields("title"))
+		Expect(err).To(BeNil())
+		Expect(cli.requests[0].URL.String()).To(Equal("http://a.foo.bar/solr/solrtest/terms"))
+		body, _ := ioutil.ReadAll(cli.requests[0].Body)
+		params, err := url.ParseQuery(string(body))
+		Expect(err).To(BeNil())
+		Expect(params.Get("distrib")).To(Equal("true"))
+		Expect(params.Get("shards")).To(Equal("http://a.foo.bar/solr/core1|http://b.foo.bar/solr/core1r,http://b.foo.bar/solr/core2"))
+		Expect(r.Terms["title"][0].Count).To(Equal(int64(30)))
+	})
+})
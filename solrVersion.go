@@ -0,0 +1,88 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const versionField = "_version_"
+
+// Versions with a special meaning when asserted on an update, any version > 1 must match exactly
+const (
+	// VersionMustExist only lets the update through when the doc exists
+	VersionMustExist int64 = 1
+	// VersionMustNotExist only lets the update through when the doc does not exist
+	VersionMustNotExist int64 = -1
+)
+
+// AssertVersion makes the update conditional on the version of the docs that do not carry their
+// own _version_, solr rejects it with a VersionConflictError when the version does not match
+func AssertVersion(version int64) func(url.Values) {
+	return func(p url.Values) {
+		p[versionField] = []string{strconv.FormatInt(version, 10)}
+	}
+}
+
+// MustExist makes the update fail with a VersionConflictError when the doc does not exist
+func MustExist() func(url.Values) {
+	return AssertVersion(VersionMustExist)
+}
+
+// MustNotExist makes the update fail with a VersionConflictError when the doc already exists
+func MustNotExist() func(url.Values) {
+	return AssertVersion(VersionMustNotExist)
+}
+
+// Version makes the partial update conditional on the version of the doc
+func (u *AtomicUpdate) Version(version int64) *AtomicUpdate {
+	u.version = version
+	return u
+}
+
+// ReadModifyWrite reads the doc with real-time get, hands it to modify and writes the returned doc
+// asserting the version that was read, modify gets a nil doc when the doc does not exist and the write
+// then asserts it still does not. On a VersionConflictError the cycle starts over, up to attempts times
+func ReadModifyWrite(ctx context.Context, cli SolrHTTPContext, locator SolrLocator, id string, attempts int, modify func(doc map[string]interface{}) (interface{}, error), opts ...func(url.Values)) error {
+	nodeUris, err := locator.GetLeadersAndReplicas(id)
+	if err != nil {
+		return err
+	}
+	if len(nodeUris) == 0 {
+		return NewSolrLeaderError(id)
+	}
+	for attempt := 0; ; attempt++ {
+		results, err := Get(ctx, cli, locator, []string{id})
+		if err != nil {
+			return err
+		}
+		doc, version := results[0].Doc, VersionMustNotExist
+		if results[0].Err != nil {
+			if _, ok := results[0].Err.(DocNotFoundError); !ok {
+				return results[0].Err
+			}
+		} else {
+			version = VersionMustExist
+			if v, ok := doc[versionField].(int64); ok {
+				version = v
+			}
+		}
+
+		updated, err := modify(doc)
+		if err != nil {
+			return err
+		}
+		if m, ok := updated.(map[string]interface{}); ok {
+			m[versionField] = version
+		}
+		writeOpts := make([]func(url.Values), 0, len(opts)+1)
+		writeOpts = append(writeOpts, opts...)
+		writeOpts = append(writeOpts, AssertVersion(version))
+		_, err = cli.UpdateContext(ctx, nodeUris, true, updated, writeOpts...)
+		if _, ok := err.(VersionConflictError); !ok || attempt+1 >= attempts {
+			return err
+		}
+		cli.Logger().Debug(fmt.Sprintf("[go-solr] version conflict on %s, attempt %d", id, attempt))
+	}
+}
@@ -0,0 +1,89 @@
+package solr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sendgrid/go-solr"
+)
+
+var _ = Describe("Optimistic Concurrency", func() {
+	var cli *fakeHTTPer
+	var solrHttp solr.SolrHTTPContext
+	var locator *fakeLocator
+	BeforeEach(func() {
+		cli = &fakeHTTPer{status: http.StatusOK}
+		var err error
+		solrHttp, err = solr.NewSolrHTTP(false, "solrtest", solr.HTTPClient(cli))
+		Expect(err).To(BeNil())
+		locator = &fakeLocator{shardNodes: map[string][]string{"shard1": {"http://leader1.foo.bar"}}}
+	})
+
+	It("returns a typed conflict without retrying", func() {
+		cli.status = http.StatusConflict
+		cli.body = `{"error":{"msg":"version conflict for shard1!a expected=1603386011406549000 actual=1603386011406549001","code":409}}`
+		retrier := solr.NewSolrHttpRetrier(solrHttp, 5, time.Millisecond)
+		err := retrier.Update([]string{"http://leader1.foo.bar"}, true, map[string]interface{}{"id": "shard1!a"}, solr.AssertVersion(1603386011406549000))
+		conflict, ok := err.(solr.VersionConflictError)
+		Expect(ok).To(BeTrue())
+		Expect(conflict.ID).To(Equal("shard1!a"))
+		Expect(conflict.ExpectedVersion).To(Equal(int64(1603386011406549000)))
+		Expect(conflict.CurrentVersion).To(Equal(int64(1603386011406549001)))
+		Expect(errors.Is(err, solr.ErrConflict)).To(BeTrue())
+		Expect(cli.requests).To(HaveLen(1))
+		Expect(cli.requests[0].URL.Query().Get("_version_")).To(Equal("1603386011406549000"))
+	})
+
+	It("reads, modifies and writes again on conflict", func() {
+		cli.statuses = []int{http.StatusOK, http.StatusConflict, http.StatusOK, http.StatusOK}
+		cli.bodies = []string{
+			`{"response":{"numFound":1,"docs":[{"id":"shard1!a","opens":1,"_version_":1603386011406549000}]}}`,
+			`{"error":{"msg":"version conflict for shard1!a expected=1603386011406549000 actual=1603386011406549001","code":409}}`,
+			`{"response":{"numFound":1,"docs":[{"id":"shard1!a","opens":2,"_version_":1603386011406549001}]}}`,
+			`{"responseHeader":{"status":0}}`,
+		}
+		err := solr.ReadModifyWrite(context.Background(), solrHttp, locator, "shard1!a", 3, func(doc map[string]interface{}) (interface{}, error) {
+			doc["opens"] = doc["opens"].(float64) + 1
+			return doc, nil
+		})
+		Expect(err).To(BeNil())
+		Expect(cli.requests).To(HaveLen(4))
+		Expect(cli.requests[3].URL.Query().Get("_version_")).To(Equal("1603386011406549001"))
+		body, _ := ioutil.ReadAll(cli.requests[3].Body)
+		Expect(string(body)).To(MatchJSON(`{"id":"shard1!a","opens":3,"_version_":1603386011406549001}`))
+	})
+
+	It("asserts a missing doc does not exist yet", func() {
+		cli.bodies = []string{`{"response":{"numFound":0,"docs":[]}}`, `{"responseHeader":{"status":0}}`}
+		err := solr.ReadModifyWrite(context.Background(), solrHttp, locator, "shard1!a", 1, func(doc map[string]interface{}) (interface{}, error) {
+			Expect(doc).To(BeNil())
+			return map[string]interface{}{"id": "shard1!a", "opens": 1}, nil
+		})
+		Expect(err).To(BeNil())
+		Expect(cli.requests[1].URL.Query().Get("_version_")).To(Equal("-1"))
+	})
+
+	It("returns the versions assigned by an update", func() {
+		cli.body = `{"responseHeader":{"status":0,"QTime":3,"rf":2,"min_rf":1},"adds":["shard1!a",1603386011406549001,"shard1!b",1603386011406549002],"deletes":["shard1!c",-1603386011406549003]}`
+		r, err := solrHttp.UpdateContext(context.Background(), []string{"http://leader1.foo.bar"}, false, []interface{}{}, solr.Versions(true))
+		Expect(err).To(BeNil())
+		Expect(cli.requests[0].URL.Query().Get("versions")).To(Equal("true"))
+		Expect(r.RF).To(Equal(2))
+		Expect(r.QTime).To(Equal(3))
+		Expect(r.Adds).To(Equal(solr.DocVersions{"shard1!a": 1603386011406549001, "shard1!b": 1603386011406549002}))
+		Expect(r.Deletes).To(Equal(solr.DocVersions{"shard1!c": -1603386011406549003}))
+	})
+
+	It("decodes versions sent as an object", func() {
+		var versions solr.DocVersions
+		Expect(json.Unmarshal([]byte(`{"a":1603386011406549001}`), &versions)).To(BeNil())
+		Expect(versions).To(Equal(solr.DocVersions{"a": 1603386011406549001}))
+		Expect(json.Unmarshal([]byte(`["a"]`), &versions)).To(Not(BeNil()))
+	})
+})
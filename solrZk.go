@@ -181,6 +181,58 @@ func (s *solrZkInstance) GetReplicasFromRoute(route string) ([]string, error) {
 	return shuffleNodes(hosts), nil
 
 }
+
+// GetShardCores returns the core urls of the live replicas of every active shard keyed by shard name,
+// for handlers like export that have to be sent to one replica of each shard
+func (s *solrZkInstance) GetShardCores() (map[string][]string, error) {
+	cs, err := s.GetClusterState()
+	if err != nil {
+		return nil, err
+	}
+	collection, ok := cs.Collections[s.collection]
+	if !ok {
+		return nil, fmt.Errorf("[go-solr] Collection %s does not exist ", s.collection)
+	}
+	cores := make(map[string][]string, len(collection.Shards))
+	for name, shard := range collection.Shards {
+		if !isShardActive(&shard) {
+			continue
+		}
+		uris := make([]string, 0, len(shard.Replicas))
+		for _, replica := range shard.Replicas {
+			if isReplicaActive(&replica) {
+				uris = append(uris, fmt.Sprintf("%s/%s", replica.BaseURL, replica.Core))
+			}
+		}
+		if len(uris) == 0 {
+			return nil, fmt.Errorf("[go-solr] no live replicas for shard %s", name)
+		}
+		cores[name] = shuffleNodes(uris)
+	}
+	return cores, nil
+}
+
+// GroupByShard groups doc ids by the name of the shard their composite id hashes to
+func (s *solrZkInstance) GroupByShard(docIDs []string) (map[string][]string, error) {
+	cs, err := s.GetClusterState()
+	if err != nil {
+		return nil, err
+	}
+	collection, ok := cs.Collections[s.collection]
+	if !ok {
+		return nil, fmt.Errorf("[go-solr] Collection %s does not exist ", s.collection)
+	}
+	groups := make(map[string][]string)
+	for _, docID := range docIDs {
+		shard, err := findShard(docID, &collection)
+		if err != nil {
+			return nil, err
+		}
+		groups[shard.Name] = append(groups[shard.Name], docID)
+	}
+	return groups, nil
+}
+
 func shuffleNodes(nodes []string) []string {
 	if len(nodes) == 1 {
 		return nodes
@@ -1,6 +1,7 @@
 package solr_test
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
@@ -27,8 +28,8 @@ func newUUID() (string, error) {
 
 var _ = Describe("Solr Client", func() {
 	var solrClient solr.SolrZK
-	var solrHttp solr.SolrHTTP
-	var solrHttpRetrier solr.SolrHTTP
+	var solrHttp solr.SolrHTTPContext
+	var solrHttpRetrier solr.SolrHTTPContext
 	var locator solr.SolrLocator
 	solrClient = solr.NewSolrZK("zk:2181", "solr", "solrtest")
 	locator = solrClient.GetSolrLocator()
@@ -323,6 +324,57 @@ var _ = Describe("Solr Client", func() {
 				Expect(r.Response.NumFound).To(BeEquivalentTo(0))
 			})
 
+			It("can real time get uncommitted docs", func() {
+				uuid, _ := newUUID()
+				id := "mycrazyshardkey5!" + uuid
+				doc := map[string]interface{}{
+					"id":         id,
+					"email":      uuid + "feldman@sendgrid.com",
+					"first_name": "shawn5" + uuid,
+					"last_name":  uuid,
+				}
+				leader, err := locator.GetLeaders(id)
+				Expect(err).To(BeNil())
+				err = solrHttp.Update(leader, true, doc, solr.Commit(false))
+				Expect(err).To(BeNil())
+				results, err := solr.Get(context.Background(), solrHttpRetrier, locator, []string{id, id + "missing"})
+				Expect(err).To(BeNil())
+				Expect(results[0].Err).To(BeNil())
+				Expect(results[0].Doc["last_name"]).To(Equal(uuid))
+				Expect(results[1].Err).To(Equal(solr.DocNotFoundError{ID: id + "missing"}))
+			})
+
+			It("can delete by id and by query", func() {
+				uuid, _ := newUUID()
+				ids := []string{"mycrazyshardkey6!" + uuid, "mycrazyshardkey7!" + uuid, "mycrazyshardkey8!" + uuid}
+				for _, id := range ids {
+					doc := map[string]interface{}{
+						"id":        id,
+						"last_name": uuid,
+					}
+					leader, err := locator.GetLeaders(id)
+					Expect(err).To(BeNil())
+					err = solrHttp.Update(leader, true, doc, solr.Commit(false))
+					Expect(err).To(BeNil())
+				}
+				results, err := solr.DeleteByID(context.Background(), solrHttpRetrier, locator, ids[:2], solr.Commit(true))
+				Expect(err).To(BeNil())
+				for _, result := range results {
+					Expect(result.Err).To(BeNil())
+				}
+				replicas, err := locator.GetReplicaUris()
+				Expect(err).To(BeNil())
+				r, err := solrHttp.Select(replicas, solr.Query("last_name:"+uuid))
+				Expect(err).To(BeNil())
+				Expect(r.Response.NumFound).To(BeEquivalentTo(1))
+
+				_, err = solr.DeleteByQuery(context.Background(), solrHttpRetrier, replicas, "last_name:"+uuid, solr.Commit(true))
+				Expect(err).To(BeNil())
+				r, err = solrHttp.Select(replicas, solr.Query("last_name:"+uuid))
+				Expect(err).To(BeNil())
+				Expect(r.Response.NumFound).To(BeEquivalentTo(0))
+			})
+
 			It("can get the shard for a route", func() {
 				shard, err := locator.GetShardFromRoute("mycrazyshardkey3!")
 				Expect(err).To(BeNil())